@@ -0,0 +1,163 @@
+package gptapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 串流片段累加器
+//
+// 依序 Add 串流片段後, 以 Response 取得與非串流模式相同結構的 CompletionsResponse
+// 片段資料異常而無法完整重組時, Err 回傳錯誤
+type StreamAccumulator struct {
+	response CompletionsResponse
+	choices  map[int]*accumulatedChoice // 以 choice index 對應
+	errs     []error                    // 重組時遇到的異常片段
+}
+
+// 單一選項的累加內容
+type accumulatedChoice struct {
+	role         string
+	content      strings.Builder
+	refusal      strings.Builder
+	toolCalls    []ToolCalls
+	arguments    []*strings.Builder // 與 toolCalls 同索引的參數片段
+	finishReason string
+	logprobs     *ChoiceLogprobs
+}
+
+func NewStreamAccumulator() *StreamAccumulator {
+	return &StreamAccumulator{
+		choices: make(map[int]*accumulatedChoice),
+	}
+}
+
+// 加入一個串流片段
+func (self *StreamAccumulator) Add(chunk ChatCompletionChunk) {
+	if self.response.ID == "" {
+		self.response.ID = chunk.ID
+	}
+	if self.response.Created == 0 {
+		self.response.Created = chunk.Created
+	}
+	if chunk.Model != "" {
+		self.response.Model = chunk.Model
+	}
+	if chunk.ServiceTier != "" {
+		self.response.Service_tier = chunk.ServiceTier
+	}
+	if chunk.SystemFingerprint != "" {
+		self.response.System_fingerprint = chunk.SystemFingerprint
+	}
+	if chunk.Usage != nil {
+		self.response.Usage = *chunk.Usage
+	}
+
+	for _, delta := range chunk.Choices {
+		choice, ok := self.choices[delta.Index]
+		if !ok {
+			choice = &accumulatedChoice{}
+			self.choices[delta.Index] = choice
+		}
+
+		if delta.Delta.Role != "" {
+			choice.role = delta.Delta.Role
+		}
+		choice.content.WriteString(delta.Delta.Content)
+		choice.refusal.WriteString(delta.Delta.Refusal)
+		if delta.FinishReason != "" {
+			choice.finishReason = delta.FinishReason
+		}
+
+		if delta.Logprobs != nil {
+			if choice.logprobs == nil {
+				choice.logprobs = &ChoiceLogprobs{}
+			}
+			choice.logprobs.Content = append(choice.logprobs.Content, delta.Logprobs.Content...)
+			choice.logprobs.Refusal = append(choice.logprobs.Refusal, delta.Logprobs.Refusal...)
+		}
+
+		for _, toolDelta := range delta.Delta.ToolCalls {
+			if err := choice.addToolCall(toolDelta); err != nil {
+				self.errs = append(self.errs, fmt.Errorf("[StreamAccumulator] Error choices[%d]: %w", delta.Index, err))
+			}
+		}
+	}
+}
+
+// 重組時遇到的錯誤, 有錯誤時 Response 的內容不完整
+func (self *StreamAccumulator) Err() error {
+	return errors.Join(self.errs...)
+}
+
+// 合併 tool 調用片段, ID/Type/Name 以首次出現為準, Arguments 依序接續
+//
+// 索引超出 ToolCallsLimit 的片段無法重組, 回傳錯誤, 避免依索引配置過大的空間
+func (self *accumulatedChoice) addToolCall(delta ToolCallDelta) error {
+	if delta.Index < 0 || delta.Index >= ToolCallsLimit {
+		return fmt.Errorf("tool call index %d out of range, limit: %d", delta.Index, ToolCallsLimit)
+	}
+	for len(self.toolCalls) <= delta.Index {
+		self.toolCalls = append(self.toolCalls, ToolCalls{})
+		self.arguments = append(self.arguments, &strings.Builder{})
+	}
+
+	call := &self.toolCalls[delta.Index]
+	if call.ID == "" {
+		call.ID = delta.ID
+	}
+	if call.Type == "" {
+		call.Type = delta.Type
+	}
+	if call.Function.Name == "" {
+		call.Function.Name = delta.Function.Name
+	}
+	self.arguments[delta.Index].WriteString(delta.Function.Arguments)
+	return nil
+}
+
+// 取得目前累加的完整回應, 需以 Err 確認重組過程沒有遺失資料
+func (self *StreamAccumulator) Response() *CompletionsResponse {
+	response := self.response
+	response.Object = "chat.completion"
+	response.Choices = make([]Choice, 0, len(self.choices))
+
+	for index, choice := range self.choices {
+		role := choice.role
+		if role == "" {
+			role = MessageContentRole_Assistant
+		}
+
+		var toolCalls []ToolCalls
+		if len(choice.toolCalls) > 0 {
+			toolCalls = make([]ToolCalls, len(choice.toolCalls))
+			for i, call := range choice.toolCalls {
+				if call.Type == "" {
+					call.Type = ToolType_Function
+				}
+				call.Function.Arguments = choice.arguments[i].String()
+				toolCalls[i] = call
+			}
+		}
+
+		response.Choices = append(response.Choices, Choice{
+			Message: AssistantMessage{
+				Role:      role,
+				Content:   choice.content.String(),
+				Refusal:   choice.refusal.String(),
+				ToolCalls: toolCalls,
+			},
+			FinishReason: choice.finishReason,
+			Index:        index,
+			Logprobs:     choice.logprobs,
+		})
+	}
+
+	sort.Slice(response.Choices, func(i, j int) bool {
+		return response.Choices[i].Index < response.Choices[j].Index
+	})
+
+	return &response
+}
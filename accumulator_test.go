@@ -0,0 +1,95 @@
+package gptapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 建立只含 tool 調用片段的串流片段
+func toolCallChunk(deltas ...ToolCallDelta) ChatCompletionChunk {
+	return ChatCompletionChunk{
+		Choices: []ChunkChoice{{Delta: ChunkDelta{ToolCalls: deltas}}},
+	}
+}
+
+func TestStreamAccumulatorToolCallsBeyondToolsLimit(t *testing.T) {
+	acc := NewStreamAccumulator()
+	for i := 0; i < ToolsLimit+10; i++ {
+		acc.Add(toolCallChunk(ToolCallDelta{Index: i, ID: "call", Function: ToolCallsFunction{Name: "f", Arguments: "{}"}}))
+	}
+
+	if err := acc.Err(); err != nil {
+		t.Fatalf("Err = %v, want nil", err)
+	}
+	if got := len(acc.Response().Choices[0].Message.ToolCalls); got != ToolsLimit+10 {
+		t.Errorf("tool calls = %d, want %d", got, ToolsLimit+10)
+	}
+}
+
+func TestStreamAccumulatorToolCallIndexOutOfRange(t *testing.T) {
+	for _, index := range []int{-1, ToolCallsLimit} {
+		acc := NewStreamAccumulator()
+		acc.Add(toolCallChunk(ToolCallDelta{Index: 0, ID: "call_0", Function: ToolCallsFunction{Name: "f", Arguments: "{}"}}))
+		acc.Add(toolCallChunk(ToolCallDelta{Index: index, ID: "call_x", Function: ToolCallsFunction{Name: "f"}}))
+
+		if err := acc.Err(); err == nil {
+			t.Errorf("index %d: Err = nil, want error", index)
+		}
+		if got := len(acc.Response().Choices[0].Message.ToolCalls); got != 1 {
+			t.Errorf("index %d: tool calls = %d, want 1", index, got)
+		}
+	}
+}
+
+func TestStreamAccumulatorReassembly(t *testing.T) {
+	chunks := []ChatCompletionChunk{
+		{ID: "chatcmpl-1", Created: 100, Model: "gpt-4o", Choices: []ChunkChoice{{Delta: ChunkDelta{Role: MessageContentRole_Assistant, Content: "Hel"}}}},
+		{Choices: []ChunkChoice{{Delta: ChunkDelta{Content: "lo"}}, {Index: 1, Delta: ChunkDelta{Content: "Hi"}}}},
+		toolCallChunk(
+			ToolCallDelta{Index: 0, ID: "call_a", Type: ToolType_Function, Function: ToolCallsFunction{Name: "get_weather", Arguments: `{"loc`}},
+			ToolCallDelta{Index: 1, ID: "call_b", Type: ToolType_Function, Function: ToolCallsFunction{Name: "get_time", Arguments: `{"tz":`}},
+		),
+		toolCallChunk(ToolCallDelta{Index: 1, Function: ToolCallsFunction{Arguments: `"UTC"}`}}),
+		toolCallChunk(ToolCallDelta{Index: 0, Function: ToolCallsFunction{Arguments: `ation":"Taipei"}`}}),
+		{Choices: []ChunkChoice{{FinishReason: "tool_calls"}, {Index: 1, FinishReason: "stop"}}},
+		{Usage: &Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15}},
+	}
+
+	acc := NewStreamAccumulator()
+	for _, chunk := range chunks {
+		acc.Add(chunk)
+	}
+	if err := acc.Err(); err != nil {
+		t.Fatalf("Err: %v", err)
+	}
+
+	want := &CompletionsResponse{
+		ID:      "chatcmpl-1",
+		Object:  "chat.completion",
+		Created: 100,
+		Model:   "gpt-4o",
+		Usage:   Usage{PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15},
+		Choices: []Choice{
+			{
+				Index:        0,
+				FinishReason: "tool_calls",
+				Message: AssistantMessage{
+					Role:    MessageContentRole_Assistant,
+					Content: "Hello",
+					ToolCalls: []ToolCalls{
+						{ID: "call_a", Type: ToolType_Function, Function: ToolCallsFunction{Name: "get_weather", Arguments: `{"location":"Taipei"}`}},
+						{ID: "call_b", Type: ToolType_Function, Function: ToolCallsFunction{Name: "get_time", Arguments: `{"tz":"UTC"}`}},
+					},
+				},
+			},
+			{
+				Index:        1,
+				FinishReason: "stop",
+				Message:      AssistantMessage{Role: MessageContentRole_Assistant, Content: "Hi"},
+			},
+		},
+	}
+	if got := acc.Response(); !reflect.DeepEqual(got, want) {
+		t.Errorf("Response = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,275 @@
+package gptapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// API 客戶端
+//
+// 透過 NewClient 搭配 ClientOption 建立, 可指向 OpenAI 或相容的閘道服務
+type Client struct {
+	apiKey       string            // API 金鑰
+	baseURL      string            // API 根網址 EX: "https://api.openai.com/v1"
+	organization string            // OpenAI-Organization 標頭
+	project      string            // OpenAI-Project 標頭
+	userAgent    string            // User-Agent 標頭
+	model        string            // 未指定模型時使用的預設模型
+	headers      map[string]string // 額外附加的標頭
+	httpClient   *http.Client      // 實際送出請求的 http client
+	retryPolicy  IRetryPolicy      // 請求失敗時的重試策略
+	onWarning    func(string)      // 請求警告的處理方式, nil 時不檢查
+}
+
+// Client 設定選項
+type ClientOption func(*Client)
+
+// 建立新 Client
+func NewClient(opts ...ClientOption) *Client {
+	client := &Client{
+		baseURL:     DefaultBaseURL,
+		model:       DefaultModel,
+		headers:     make(map[string]string),
+		httpClient:  &http.Client{},
+		retryPolicy: DefaultRetryPolicy(),
+	}
+
+	for _, opt := range opts {
+		opt(client)
+	}
+
+	return client
+}
+
+// 設定 API 金鑰
+func WithAPIKey(apiKey string) ClientOption {
+	return func(c *Client) {
+		c.apiKey = apiKey
+	}
+}
+
+// 設定 API 根網址, 用於 Azure/相容閘道或本地測試服務
+func WithBaseURL(baseURL string) ClientOption {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// 設定 OpenAI-Organization 標頭
+func WithOrganization(organization string) ClientOption {
+	return func(c *Client) {
+		c.organization = organization
+	}
+}
+
+// 設定 OpenAI-Project 標頭
+func WithProject(project string) ClientOption {
+	return func(c *Client) {
+		c.project = project
+	}
+}
+
+// 設定自訂 http.Client
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		if httpClient != nil {
+			c.httpClient = httpClient
+		}
+	}
+}
+
+// 設定預設模型
+func WithDefaultModel(model string) ClientOption {
+	return func(c *Client) {
+		c.model = model
+	}
+}
+
+// 設定 User-Agent 標頭
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.userAgent = userAgent
+	}
+}
+
+// 附加自訂標頭 EX: Azure 的 "api-key"
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		c.headers[key] = value
+	}
+}
+
+// 設定重試策略, 傳入 nil 或 NoRetryPolicy() 表示不重試
+func WithRetryPolicy(policy IRetryPolicy) ClientOption {
+	return func(c *Client) {
+		if policy == nil {
+			policy = NoRetryPolicy()
+		}
+		c.retryPolicy = policy
+	}
+}
+
+// 設定請求警告的處理方式, Completions 送出前以模型目錄檢查請求 (參考 ChatCompletionRequest.Warnings) 並逐一回報
+//
+//	gptapi.WithWarningHandler(func(warning string) { log.Println("gptapi:", warning) })
+func WithWarningHandler(handler func(warning string)) ClientOption {
+	return func(c *Client) {
+		c.onWarning = handler
+	}
+}
+
+// 回報請求警告, 未設定 WithWarningHandler 時不檢查
+func (self *Client) warn(reqBody *ChatCompletionRequest) {
+	if self.onWarning == nil {
+		return
+	}
+	for _, warning := range reqBody.Warnings() {
+		self.onWarning(warning)
+	}
+}
+
+// 建立以 Client 預設模型為準的 Completions 請求
+func (self *Client) NewCompletionsRequest(maxToken int) ChatCompletionRequest {
+	return ChatCompletionRequest{
+		Model:     self.model,
+		MaxTokens: maxToken,
+	}
+}
+
+// 建立已附加共用標頭的 http 請求
+func (self *Client) newRequest(ctx context.Context, method, path string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, self.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if self.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+self.apiKey)
+	}
+	if self.organization != "" {
+		req.Header.Set("OpenAI-Organization", self.organization)
+	}
+	if self.project != "" {
+		req.Header.Set("OpenAI-Project", self.project)
+	}
+	if self.userAgent != "" {
+		req.Header.Set("User-Agent", self.userAgent)
+	}
+	for key, value := range self.headers {
+		req.Header.Set(key, value)
+	}
+
+	return req, nil
+}
+
+// 送出請求並依重試策略重送, 成功時回傳狀態 200 且內文尚未讀取的回應
+//
+// 非 200 回應轉為 *APIError, 請求的 ctx 被取消或逾時時回傳 ctx.Err()
+// @idempotent 請求重送是否安全, 參考 IRetryPolicy
+func (self *Client) send(req *http.Request, idempotent bool) (*http.Response, error) {
+	ctx := req.Context()
+	for attempt := 1; ; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to reset request body: %v", err)
+			}
+			req.Body = body
+		}
+
+		resp, err := self.sendOnce(req)
+		if err == nil {
+			return resp, nil
+		}
+
+		wait, retry := self.retryPolicy.Retry(attempt, idempotent, err)
+		if !retry {
+			return nil, err
+		}
+
+		if err := sleepContext(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// 送出單次請求
+func (self *Client) sendOnce(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	resp, err := self.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(resp.Body)
+		if err != nil && ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, newAPIError(resp, body)
+	}
+
+	return resp, nil
+}
+
+// 送出請求並讀取回應內文
+func (self *Client) do(req *http.Request, idempotent bool) ([]byte, error) {
+	ctx := req.Context()
+	resp, err := self.send(req, idempotent)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+
+	return body, nil
+}
+
+// 以 json 格式送出請求並將回應解析至 out
+//
+// @reqBody 為 nil 時不帶內文
+// @idempotent 請求重送是否安全, 參考 IRetryPolicy
+func (self *Client) doJSON(ctx context.Context, method, path string, reqBody, out interface{}, idempotent bool) error {
+	var body io.Reader
+	if reqBody != nil {
+		reqData, err := json.Marshal(reqBody)
+		if err != nil {
+			return fmt.Errorf("failed to marshal request body: %v", err)
+		}
+		body = bytes.NewReader(reqData)
+	}
+
+	req, err := self.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+
+	respBody, err := self.do(req, idempotent)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("無法解析回應: %v", err)
+	}
+
+	return nil
+}
@@ -1,6 +1,12 @@
 package gptapi
 
 const (
+	// 未指定模型時使用的預設向量模型
+	DefaultEmbeddingModel string = "text-embedding-3-small"
+
+	// 未指定模型時使用的預設模型
+	// gpt-4o vs gpt-4o-mini 模型 token 價值不同, mini 消耗更多 token 但價格更便宜 4o VS 4o-mini 價格大約為 1:5
+	DefaultModel string = "gpt-4o-mini"
 
 	// 低解析度圖像
 	// 限制 Size: 512x512 消耗Token: 85
@@ -12,6 +18,14 @@ const (
 	// 限制 Size 上限:  768x2000
 	ImageDetailMode_High string = "high"
 
+	// 由模型依圖片尺寸自動選擇解析度
+	ImageDetailMode_Auto string = "auto"
+
+	ImageType_JPEG string = "image/jpeg"
+	ImageType_PNG  string = "image/png"
+	ImageType_GIF  string = "image/gif"
+	ImageType_WEBP string = "image/webp"
+
 	// 圖片容量大小限制
 	ImageSizeLimit int = 20 * 1024 * 1024
 
@@ -22,23 +36,87 @@ const (
 	MessageFinishType_Null          string = "null"           // API 回應仍在進行中或不完整
 
 	MessageContentRole_System    string = "system"    // 提示內文,用來影響AI的行為與回應風格
+	MessageContentRole_Developer string = "developer" // 開發者指示, 推理模型用來取代 system
 	MessageContentRole_User      string = "user"      // 使用者輸入內容
 	MessageContentRole_Assistant string = "assistant" // 模型的實際回應
 	MessageContentRole_Tool      string = "tool"      // 工具處理訊息
 
-	MessageContentType_Text  string = "text"
-	MessageContentType_Image string = "image_url"
-
-	Url_Batches             string = "https://api.openai.com/v1/batches"
-	Url_ListBatch           string = "https://api.openai.com/v1/batches"                   // 查詢已存在的批次任務
-	Url_RetrieveBatch       string = "https://api.openai.com/v1/batches/{batch_id}"        // 查詢指定批次任務
-	Url_CancelBatch         string = "https://api.openai.com/v1/batches/{batch_id}/cancel" // 取消指定批次任務
-	Url_Completions         string = "https://api.openai.com/v1/chat/completions"          // 模型演算
-	Url_UploadFiles         string = "https://api.openai.com/v1/files"                     // 上傳檔案
-	Url_ListFiles           string = "https://api.openai.com/v1/files"                     // 取得檔案列表
-	Url_RetrieveFile        string = "https://api.openai.com/v1/files/{file_id}"           // 檢索檔案資訊
-	Url_DeleteFile          string = "https://api.openai.com/v1/files/{file_id}"           // 刪除檔案
-	Url_RetrueveFileContent string = "https://api.openai.com/v1/files/{file_id}/content"   // 檢索檔案內文
+	ReasoningEffort_Minimal string = "minimal" // 推理模型思考程度, 最少推理 token
+	ReasoningEffort_Low     string = "low"
+	ReasoningEffort_Medium  string = "medium" // 推理模型預設值
+	ReasoningEffort_High    string = "high"
+
+	MessageContentType_Text       string = "text"
+	MessageContentType_Image      string = "image_url"
+	MessageContentType_InputAudio string = "input_audio" // 音訊輸入, 需使用支援音訊的模型
+	MessageContentType_File       string = "file"        // 檔案輸入 EX: pdf
+
+	AudioFormat_WAV string = "wav"
+	AudioFormat_MP3 string = "mp3"
+
+	// 單一請求可包含的圖片數量上限
+	ImageCountLimit int = 500
+	// 單一請求的內容大小上限
+	PayloadSizeLimit int = 50 * 1024 * 1024
+
+	ResponseFormatType_Text       string = "text"        // 一般文字輸出
+	ResponseFormatType_JSONObject string = "json_object" // JSON 模式, 保證輸出為合法 JSON, 提示中需提及 JSON
+	ResponseFormatType_JSONSchema string = "json_schema" // 結構化輸出, 輸出符合指定的 JSON Schema
+
+	// 單一請求可提供的工具數量上限
+	ToolsLimit int = 128
+
+	// 串流重組時單一回應可容納的工具呼叫數量上限, 平行呼叫可多次呼叫同一工具, 不受 ToolsLimit 限制
+	ToolCallsLimit int = 4096
+
+	ToolType_Function string = "function" // 工具類型, 目前只有函數
+
+	ToolChoice_None     string = "none"     // 模型不呼叫任何工具, 只產生訊息
+	ToolChoice_Auto     string = "auto"     // 模型自行決定是否呼叫工具, 有提供工具時的預設值
+	ToolChoice_Required string = "required" // 模型必須呼叫一個或多個工具
+
+	EmbeddingEncoding_Float  string = "float"  // 向量以浮點數陣列回傳
+	EmbeddingEncoding_Base64 string = "base64" // 向量以 base64 編碼的 float32 回傳, 傳輸量較小
+
+	EmbeddingInputsLimit        int = 2048   // 單一請求的輸入數量上限
+	EmbeddingInputTokensLimit   int = 8191   // 單一輸入的 token 上限
+	EmbeddingRequestTokensLimit int = 300000 // 單一請求所有輸入合計的 token 上限
+
+	Encoding_CL100K string = "cl100k_base" // gpt-4, gpt-3.5-turbo, text-embedding-3 使用的詞彙表
+	Encoding_O200K  string = "o200k_base"  // gpt-4o, gpt-4.1, o 系列推理模型使用的詞彙表
+
+	// API 預設根網址
+	DefaultBaseURL string = "https://api.openai.com/v1"
+
+	Path_Batches             string = "/batches"
+	Path_ListBatch           string = "/batches"                   // 查詢已存在的批次任務
+	Path_RetrieveBatch       string = "/batches/{batch_id}"        // 查詢指定批次任務
+	Path_CancelBatch         string = "/batches/{batch_id}/cancel" // 取消指定批次任務
+	Path_Completions         string = "/chat/completions"          // 模型演算
+	Path_UploadFiles         string = "/files"                     // 上傳檔案
+	Path_ListFiles           string = "/files"                     // 取得檔案列表
+	Path_RetrieveFile        string = "/files/{file_id}"           // 檢索檔案資訊
+	Path_DeleteFile          string = "/files/{file_id}"           // 刪除檔案
+	Path_RetrieveFileContent string = "/files/{file_id}/content"   // 檢索檔案內文
+	Path_Embeddings          string = "/embeddings"                // 文字向量
+	Path_ListModels          string = "/models"                    // 取得模型列表
+	Path_RetrieveModel       string = "/models/{model}"            // 查詢指定模型
+	Path_DeleteModel         string = "/models/{model}"            // 刪除微調模型
+
+	Url_Batches             string = DefaultBaseURL + Path_Batches
+	Url_ListBatch           string = DefaultBaseURL + Path_ListBatch           // 查詢已存在的批次任務
+	Url_RetrieveBatch       string = DefaultBaseURL + Path_RetrieveBatch       // 查詢指定批次任務
+	Url_CancelBatch         string = DefaultBaseURL + Path_CancelBatch         // 取消指定批次任務
+	Url_Completions         string = DefaultBaseURL + Path_Completions         // 模型演算
+	Url_UploadFiles         string = DefaultBaseURL + Path_UploadFiles         // 上傳檔案
+	Url_ListFiles           string = DefaultBaseURL + Path_ListFiles           // 取得檔案列表
+	Url_RetrieveFile        string = DefaultBaseURL + Path_RetrieveFile        // 檢索檔案資訊
+	Url_DeleteFile          string = DefaultBaseURL + Path_DeleteFile          // 刪除檔案
+	Url_RetrueveFileContent string = DefaultBaseURL + Path_RetrieveFileContent // 檢索檔案內文
+	Url_Embeddings          string = DefaultBaseURL + Path_Embeddings          // 文字向量
+	Url_ListModels          string = DefaultBaseURL + Path_ListModels          // 取得模型列表
+	Url_RetrieveModel       string = DefaultBaseURL + Path_RetrieveModel       // 查詢指定模型
+	Url_DeleteModel         string = DefaultBaseURL + Path_DeleteModel         // 刪除微調模型
 )
 
 // 批次處理目的標籤
@@ -69,6 +147,10 @@ const (
 	// Batch 任務類型 用於指定如何解析內文
 	BatchType_Completions string = "completions"
 	BatchType_Embeddings  string = "embeddings"
+
+	// Batch 任務的 API 路徑, 需與檔案中每筆 Record 的 url 相同
+	BatchEndpoint_Completions string = "/v1/chat/completions" // 使用 NewJsonlFile 寫入的 Record
+	BatchEndpoint_Embeddings  string = "/v1/embeddings"       // 使用 NewEmbeddingsJsonlFile 寫入的 EmbeddingsRecord
 )
 
 // 'fine-tune', 'assistants', 'batch', 'user_data', 'responses', 'vision'
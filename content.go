@@ -0,0 +1,251 @@
+package gptapi
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	"io"
+	"net/http"
+	"slices"
+)
+
+// 多段內文建立器, 依加入順序組合文字, 圖片, 音訊與檔案段落
+//
+// 加入段落時發生的錯誤會保留到 Parts 或 Message 時一併回傳
+//
+//	message, err := gptapi.NewContentBuilder().
+//		AddText("比較這兩張圖片").
+//		AddImageURL("https://example.com/a.png", gptapi.ImageDetailMode_Auto).
+//		AddImageFile("b.jpg", gptapi.ImageDetailMode_Low).
+//		Message()
+type ContentBuilder struct {
+	parts []ContentPart
+	errs  []error
+}
+
+func NewContentBuilder() *ContentBuilder {
+	return &ContentBuilder{}
+}
+
+func (self *ContentBuilder) addErrorf(format string, args ...interface{}) *ContentBuilder {
+	self.errs = append(self.errs, fmt.Errorf("[ContentBuilder] Error "+format, args...))
+	return self
+}
+
+// 加入文字段落, API 不接受空白的文字段落
+func (self *ContentBuilder) AddText(text string) *ContentBuilder {
+	if text == "" {
+		return self.addErrorf("empty text")
+	}
+
+	self.parts = append(self.parts, ContentPart{
+		Type: MessageContentType_Text,
+		Text: text,
+	})
+	return self
+}
+
+// 加入圖片段落
+//
+// @url 圖片"網址"或"base64編碼圖片"
+// @detail 圖像模式 ImageDetailMode_Low, ImageDetailMode_High, ImageDetailMode_Auto, 空字串由模型自動決定
+func (self *ContentBuilder) AddImageURL(url, detail string) *ContentBuilder {
+	if url == "" {
+		return self.addErrorf("empty image url")
+	}
+	if !slices.Contains([]string{"", ImageDetailMode_Low, ImageDetailMode_High, ImageDetailMode_Auto}, detail) {
+		return self.addErrorf("invalid image detail: %q", detail)
+	}
+
+	self.parts = append(self.parts, ContentPart{
+		Type: MessageContentType_Image,
+		ImageURL: &ContentImageData{
+			URL:    url,
+			Detail: detail,
+		},
+	})
+	return self
+}
+
+// 讀取圖片檔案加入圖片段落, detail 為 low 或 high 時依解析度模式縮小圖片
+func (self *ContentBuilder) AddImageFile(imagepath, detail string) *ContentBuilder {
+	url, err := EncodeImageFile(imagepath, imageOptionsFor(detail))
+	if err != nil {
+		return self.addErrorf("%v", err)
+	}
+	return self.AddImageURL(url, detail)
+}
+
+// 讀取圖片加入圖片段落, detail 為 low 或 high 時依解析度模式縮小圖片
+func (self *ContentBuilder) AddImageReader(reader io.Reader, detail string) *ContentBuilder {
+	url, err := EncodeImageReader(reader, imageOptionsFor(detail))
+	if err != nil {
+		return self.addErrorf("%v", err)
+	}
+	return self.AddImageURL(url, detail)
+}
+
+// 以圖片檔案內容加入圖片段落, detail 為 low 或 high 時依解析度模式縮小圖片
+func (self *ContentBuilder) AddImageBytes(data []byte, detail string) *ContentBuilder {
+	url, err := EncodeImageBytes(data, imageOptionsFor(detail))
+	if err != nil {
+		return self.addErrorf("%v", err)
+	}
+	return self.AddImageURL(url, detail)
+}
+
+// 以 image.Image 加入圖片段落, detail 為 low 或 high 時依解析度模式縮小圖片
+func (self *ContentBuilder) AddImage(img image.Image, detail string) *ContentBuilder {
+	url, err := EncodeImage(img, imageOptionsFor(detail))
+	if err != nil {
+		return self.addErrorf("%v", err)
+	}
+	return self.AddImageURL(url, detail)
+}
+
+// 只有 low 與 high 有縮放規則
+func imageOptionsFor(detail string) ImageOptions {
+	if detail == ImageDetailMode_Low || detail == ImageDetailMode_High {
+		return ImageOptions{Detail: detail}
+	}
+	return ImageOptions{}
+}
+
+// 加入音訊段落
+//
+// @format 音訊格式 AudioFormat_WAV 或 AudioFormat_MP3
+func (self *ContentBuilder) AddAudio(data []byte, format string) *ContentBuilder {
+	if len(data) == 0 {
+		return self.addErrorf("empty audio data")
+	}
+	if format != AudioFormat_WAV && format != AudioFormat_MP3 {
+		return self.addErrorf("invalid audio format: %q", format)
+	}
+
+	self.parts = append(self.parts, ContentPart{
+		Type: MessageContentType_InputAudio,
+		InputAudio: &ContentAudioData{
+			Data:   base64.StdEncoding.EncodeToString(data),
+			Format: format,
+		},
+	})
+	return self
+}
+
+// 以已上傳檔案的 id 加入檔案段落
+func (self *ContentBuilder) AddFileID(fileId string) *ContentBuilder {
+	if fileId == "" {
+		return self.addErrorf("empty file id")
+	}
+
+	self.parts = append(self.parts, ContentPart{
+		Type: MessageContentType_File,
+		File: &ContentFileData{FileID: fileId},
+	})
+	return self
+}
+
+// 以檔案內容加入檔案段落, 依內容判斷檔案格式
+func (self *ContentBuilder) AddFileData(filename string, data []byte) *ContentBuilder {
+	if filename == "" {
+		return self.addErrorf("empty filename")
+	}
+	if len(data) == 0 {
+		return self.addErrorf("empty file data")
+	}
+
+	self.parts = append(self.parts, ContentPart{
+		Type: MessageContentType_File,
+		File: &ContentFileData{
+			Filename: filename,
+			FileData: fmt.Sprintf("data:%s;base64,%s", http.DetectContentType(data), base64.StdEncoding.EncodeToString(data)),
+		},
+	})
+	return self
+}
+
+// 取得已加入的段落, 回傳加入段落時與檢查時發生的所有錯誤
+func (self *ContentBuilder) Parts() ([]ContentPart, error) {
+	errs := append([]error(nil), self.errs...)
+	if len(self.parts) == 0 {
+		errs = append(errs, errors.New("[ContentBuilder] Error no content parts"))
+	}
+	if err := validateContentParts(self.parts); err != nil {
+		errs = append(errs, err)
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return append([]ContentPart(nil), self.parts...), nil
+}
+
+// 以已加入的段落建立使用者訊息
+func (self *ContentBuilder) Message() (IMessage, error) {
+	parts, err := self.Parts()
+	if err != nil {
+		return nil, err
+	}
+	return &UserMessage{
+		Role:    MessageContentRole_User,
+		Content: parts,
+	}, nil
+}
+
+// 檢查段落中的空白文字, 圖片數量與內容大小
+func validateContentParts(parts []ContentPart) error {
+	for _, part := range parts {
+		if part.Type == MessageContentType_Text && part.Text == "" {
+			return errors.New("[ContentBuilder] Error empty text part")
+		}
+	}
+
+	images, size := contentPartsStats(parts)
+	if images > ImageCountLimit {
+		return fmt.Errorf("[ContentBuilder] Error too many images: %d, limit: %d", images, ImageCountLimit)
+	}
+	if size > PayloadSizeLimit {
+		return fmt.Errorf("[ContentBuilder] Error payload too large: %d bytes, limit: %d", size, PayloadSizeLimit)
+	}
+	return nil
+}
+
+// 統計段落中的圖片數量與內容大小
+func contentPartsStats(parts []ContentPart) (images int, size int) {
+	for _, part := range parts {
+		size += len(part.Text)
+		if part.ImageURL != nil {
+			images++
+			size += len(part.ImageURL.URL)
+		}
+		if part.InputAudio != nil {
+			size += len(part.InputAudio.Data)
+		}
+		if part.File != nil {
+			size += len(part.File.FileData)
+		}
+	}
+	return images, size
+}
+
+// 取得訊息中的多段內文, 內文不是多段內文時回傳 nil
+func messageContentParts(message IMessage) []ContentPart {
+	var content IContent
+	switch m := message.(type) {
+	case *UserMessage:
+		content = m.Content
+	case UserMessage:
+		content = m.Content
+	case *SystemMessage:
+		content = m.Content
+	case SystemMessage:
+		content = m.Content
+	case *DeveloperMessage:
+		content = m.Content
+	case DeveloperMessage:
+		content = m.Content
+	}
+
+	parts, _ := content.([]ContentPart)
+	return parts
+}
@@ -0,0 +1,151 @@
+package gptapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// 對話紀錄, 保存系統提示與訊息並在每次呼叫模型後自動附加回應
+//
+// 可用 json 編碼保存, 之後以 json 解碼還原繼續對話
+// 非並行安全, 同一對話不應同時由多個 goroutine 使用
+//
+//	conversation := gptapi.NewConversation("你是客服助理")
+//	conversation.AddUser("訂單何時出貨?")
+//	response, err := conversation.Complete(ctx, client, gptapi.ChatCompletionRequest{Model: "gpt-4o-mini"})
+type Conversation struct {
+	System   string           // 系統提示, 送出時放在訊息最前面, 空字串表示不使用
+	Messages []IMessage       // 對話訊息, 不含系統提示
+	Strategy IHistoryStrategy // 送出前套用的紀錄處理策略, 只影響送出的訊息不修改對話紀錄, nil 表示送出完整紀錄
+}
+
+func NewConversation(system string) *Conversation {
+	return &Conversation{
+		System: system,
+	}
+}
+
+// 附加訊息
+func (self *Conversation) AddMessages(messages ...IMessage) *Conversation {
+	self.Messages = append(self.Messages, messages...)
+	return self
+}
+
+// 附加使用者文字訊息
+func (self *Conversation) AddUser(text string) *Conversation {
+	return self.AddMessages(NewUserTextMessage(text))
+}
+
+// 送出用的完整訊息, 含系統提示
+func (self *Conversation) History() []IMessage {
+	history := make([]IMessage, 0, len(self.Messages)+1)
+	if self.System != "" {
+		history = append(history, NewSystemTextMessage(self.System))
+	}
+	return append(history, self.Messages...)
+}
+
+// 由 req 的設定建立請求, 訊息為對話紀錄加上 req 中的訊息
+func (self *Conversation) Request(req ChatCompletionRequest) ChatCompletionRequest {
+	req.Messages = append(self.History(), req.Messages...)
+	return req
+}
+
+// 送出用的訊息, 對話紀錄加上本次新增的訊息後套用紀錄處理策略
+func (self *Conversation) prepare(ctx context.Context, messages []IMessage) ([]IMessage, error) {
+	outgoing := append(self.History(), messages...)
+	if self.Strategy == nil {
+		return outgoing, nil
+	}
+	return self.Strategy.Apply(ctx, outgoing)
+}
+
+// 呼叫模型並將 req 中的訊息與模型回應附加到對話紀錄
+//
+// req 提供模型與參數設定, req 中的訊息視為本次新增的訊息
+// 送出前對完整訊息 (含 req 中的訊息) 套用 Strategy, 對話紀錄仍保存完整訊息
+// 回應包含工具呼叫時, 需自行執行工具並以 AddMessages 附加結果, 或改用 Run
+// 發生錯誤時不修改對話紀錄
+func (self *Conversation) Complete(ctx context.Context, client *Client, req ChatCompletionRequest) (*CompletionsResponse, error) {
+	outgoing, err := self.prepare(ctx, req.Messages)
+	if err != nil {
+		return nil, err
+	}
+
+	request := req
+	request.Messages = outgoing
+	response, err := client.CompletionsContext(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+	if len(response.Choices) == 0 {
+		return response, errors.New("[Conversation] Error response has no choices")
+	}
+
+	message := response.Choices[0].Message
+	self.Messages = append(self.Messages, req.Messages...)
+	self.Messages = append(self.Messages, &message)
+	return response, nil
+}
+
+// 以 RunConversation 執行工具呼叫流程, 並將過程中的模型回應與工具結果附加到對話紀錄
+//
+// 發生錯誤時不修改對話紀錄, 避免留下沒有對應結果的工具呼叫
+func (self *Conversation) Run(ctx context.Context, client *Client, req ChatCompletionRequest, registry *ToolRegistry, opts ...RunOption) (*RunResult, error) {
+	added := req.Messages
+	outgoing, err := self.prepare(ctx, added)
+	if err != nil {
+		return nil, err
+	}
+	req.Messages = outgoing
+
+	result, err := client.RunConversation(ctx, req, registry, opts...)
+	if err != nil {
+		return result, err
+	}
+
+	self.Messages = append(self.Messages, added...)
+	self.Messages = append(self.Messages, result.Messages[len(outgoing):]...)
+	return result, nil
+}
+
+// 複製目前的對話紀錄, 分支後的新增訊息互不影響
+//
+// 訊息本身共用, 修改已存在的訊息內容會影響所有分支
+func (self *Conversation) Fork() *Conversation {
+	return &Conversation{
+		System:   self.System,
+		Messages: append([]IMessage(nil), self.Messages...),
+		Strategy: self.Strategy,
+	}
+}
+
+// 對話紀錄 json 格式
+type conversationJSON struct {
+	System   string   `json:"system,omitempty"`
+	Messages Messages `json:"messages"`
+}
+
+func (self *Conversation) MarshalJSON() ([]byte, error) {
+	messages := Messages(self.Messages)
+	if messages == nil {
+		messages = Messages{}
+	}
+	return json.Marshal(conversationJSON{
+		System:   self.System,
+		Messages: messages,
+	})
+}
+
+func (self *Conversation) UnmarshalJSON(js []byte) error {
+	data := conversationJSON{}
+	if err := json.Unmarshal(js, &data); err != nil {
+		return fmt.Errorf("[Conversation] Error %v", err)
+	}
+
+	self.System = data.System
+	self.Messages = data.Messages
+	return nil
+}
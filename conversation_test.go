@@ -0,0 +1,95 @@
+package gptapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestConversationJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		system   string
+		messages []IMessage
+	}{
+		{"empty", "", nil},
+		{"system only", "你是客服助理", nil},
+		{"text messages", "你是客服助理", []IMessage{
+			&DeveloperMessage{Role: MessageContentRole_Developer, Content: "回答使用繁體中文"},
+			&UserMessage{Role: MessageContentRole_User, Name: "alice", Content: "訂單何時出貨?"},
+			&AssistantMessage{Role: MessageContentRole_Assistant, Content: "預計明天出貨"},
+		}},
+		{"content parts", "", []IMessage{
+			&SystemMessage{Role: MessageContentRole_System, Content: []ContentPart{{Type: "text", Text: "只回答圖片相關問題"}}},
+			&UserMessage{Role: MessageContentRole_User, Content: []ContentPart{
+				{Type: "text", Text: "這是什麼?"},
+				{Type: "image_url", ImageURL: &ContentImageData{URL: "https://example.com/cat.png", Detail: ImageDetailMode_Low}},
+			}},
+		}},
+		{"tool calls", "", []IMessage{
+			&UserMessage{Role: MessageContentRole_User, Content: "台北天氣?"},
+			&AssistantMessage{Role: MessageContentRole_Assistant, ToolCalls: []ToolCalls{
+				{ID: "call_1", Type: "function", Function: ToolCallsFunction{Name: "get_weather", Arguments: `{"city":"台北"}`}},
+				{ID: "call_2", Type: "function", Function: ToolCallsFunction{Name: "get_time", Arguments: `{}`}},
+			}},
+			&ToolMessage{Role: MessageContentRole_Tool, ToolCallId: "call_1", Content: `{"temp":25}`},
+			&ToolMessage{Role: MessageContentRole_Tool, ToolCallId: "call_2", Content: []ContentPart{{Type: "text", Text: "12:00"}}},
+			&AssistantMessage{Role: MessageContentRole_Assistant, Content: "台北 25 度"},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversation := NewConversation(tt.system).AddMessages(tt.messages...)
+			js, err := json.Marshal(conversation)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+
+			restored := &Conversation{}
+			if err := json.Unmarshal(js, restored); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if restored.System != tt.system {
+				t.Errorf("System = %q, want %q", restored.System, tt.system)
+			}
+			if len(restored.Messages) != len(tt.messages) {
+				t.Fatalf("Messages = %d, want %d", len(restored.Messages), len(tt.messages))
+			}
+			for i, message := range restored.Messages {
+				if !reflect.DeepEqual(message, tt.messages[i]) {
+					t.Errorf("Messages[%d] = %#v, want %#v", i, message, tt.messages[i])
+				}
+			}
+
+			again, err := json.Marshal(restored)
+			if err != nil {
+				t.Fatalf("Marshal restored: %v", err)
+			}
+			if string(again) != string(js) {
+				t.Errorf("json = %s, want %s", again, js)
+			}
+		})
+	}
+}
+
+func TestConversationUnmarshalJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		js   string
+	}{
+		{"invalid json", `{`},
+		{"messages not array", `{"messages":{}}`},
+		{"unknown role", `{"messages":[{"role":"narrator","content":"hi"}]}`},
+		{"invalid content", `{"messages":[{"role":"user","content":1}]}`},
+		{"invalid tool calls", `{"messages":[{"role":"assistant","tool_calls":"call_1"}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := json.Unmarshal([]byte(tt.js), &Conversation{}); err == nil {
+				t.Error("error = nil, want error")
+			}
+		})
+	}
+}
@@ -79,8 +79,16 @@ type FileInfo struct {
 
 // 批次檔案規定格式 jsonl
 type Record struct {
-	CustomID string             `json:"custom_id"` // 自定義請求名稱
-	Method   string             `json:"method"`    // http 傳輸方式
-	URL      string             `json:"url"`       // api 路徑
-	Body     completionsRequest `json:"body"`      // api 內容
+	CustomID string                `json:"custom_id"` // 自定義請求名稱
+	Method   string                `json:"method"`    // http 傳輸方式
+	URL      string                `json:"url"`       // api 路徑
+	Body     ChatCompletionRequest `json:"body"`      // api 內容
+}
+
+// 文字向量批次檔案規定格式 jsonl, 以 NewEmbeddingsJsonlFile 寫入
+type EmbeddingsRecord struct {
+	CustomID string            `json:"custom_id"` // 自定義請求名稱
+	Method   string            `json:"method"`    // http 傳輸方式
+	URL      string            `json:"url"`       // api 路徑, 固定為 BatchEndpoint_Embeddings
+	Body     EmbeddingsRequest `json:"body"`      // api 內容
 }
@@ -17,6 +17,13 @@ type SystemMessage struct {
 	Content IContent `json:"content"`        // 內文
 }
 
+// 開發者指示訊息, 推理模型以此取代 SystemMessage
+type DeveloperMessage struct {
+	Name    string   `json:"name,omitempty"` // 用來區分相同 role 下不同的參與者
+	Role    string   `json:"role"`           // 訊息來源角色
+	Content IContent `json:"content"`        // 內文
+}
+
 type UserMessage struct {
 	Name    string   `json:"name,omitempty"` // 用來區分相同 role 下不同的參與者
 	Role    string   `json:"role"`           // 訊息來源角色
@@ -27,11 +34,11 @@ func (self *UserMessage) Contents() string { return "" }
 
 // AI 回應訊息
 type AssistantMessage struct {
-	Name      string      `json:"name,omitempty"`    // 用來區分相同 role 下不同的參與者
-	Role      string      `json:"role"`              // 訊息來源角色
-	Content   string      `json:"content"`           // 內文
-	Refusal   string      `json:"refusal,omitempty"` // 拒絕回應原因
-	ToolCalls []ToolCalls `json:"tool_calls"`        // 本次回應AI使用到的 tool 調用資訊
+	Name      string      `json:"name,omitempty"`       // 用來區分相同 role 下不同的參與者
+	Role      string      `json:"role"`                 // 訊息來源角色
+	Content   string      `json:"content"`              // 內文
+	Refusal   string      `json:"refusal,omitempty"`    // 拒絕回應原因
+	ToolCalls []ToolCalls `json:"tool_calls,omitempty"` // 本次回應AI使用到的 tool 調用資訊
 }
 
 // Tool 處理訊息
@@ -54,39 +61,66 @@ func (self *ContentText) Contents() string {
 	return string(js)
 }
 
-// IContent實作 包含圖片內文
-type ContentImage struct {
-	Type     string            `json:"type"`                // 文類型
-	Text     string            `json:"text,omitempty"`      // 內文
-	ImageURL *ContentImageData `json:"image_url,omitempty"` // 圖片內容
+// IContent實作 多段內文中的單一段落, 依 Type 使用對應欄位
+//
+// 可用 ContentBuilder 組合文字, 圖片, 音訊與檔案
+type ContentPart struct {
+	Type       string            `json:"type"`                  // 段落類型 EX: "text", "image_url", "input_audio", "file"
+	Text       string            `json:"text,omitempty"`        // 內文
+	ImageURL   *ContentImageData `json:"image_url,omitempty"`   // 圖片內容
+	InputAudio *ContentAudioData `json:"input_audio,omitempty"` // 音訊內容
+	File       *ContentFileData  `json:"file,omitempty"`        // 檔案內容
 }
 
-func (self *ContentImage) Contents() string {
+func (self *ContentPart) Contents() string {
 	js, _ := json.Marshal(self)
 	return string(js)
 }
 
+// Deprecated: 內文段落已不限於圖片, 請改用 ContentPart
+type ContentImage = ContentPart
+
 // 圖片內文結構
 type ContentImageData struct {
-	URL    string `json:"url"`    // 圖片"網址"或"base64編碼圖片"
-	Detail string `json:"detail"` // 圖像模式
+	URL    string `json:"url"`              // 圖片"網址"或"base64編碼圖片"
+	Detail string `json:"detail,omitempty"` // 圖像模式, 空字串時由模型自動決定
+}
+
+// 音訊內文結構
+type ContentAudioData struct {
+	Data   string `json:"data"`   // base64 編碼音訊
+	Format string `json:"format"` // 音訊格式 EX: "wav", "mp3"
+}
+
+// 檔案內文結構, FileID 與 FileData 擇一使用
+type ContentFileData struct {
+	FileID   string `json:"file_id,omitempty"`   // 已上傳檔案的 id
+	Filename string `json:"filename,omitempty"`  // 檔案名稱, 使用 FileData 時需提供
+	FileData string `json:"file_data,omitempty"` // base64 編碼的檔案 data URL
 }
 
 /////// IToolChoice 實作區塊
 
+// IToolChoice實作 以字串指定工具選擇模式 EX: "none", "auto", "required"
 type ToolChoiceString string
 
-func (self *ToolChoiceString) Contents() string {
-	return string(*self)
+func (self ToolChoiceString) Contents() string {
+	return string(self)
 }
 
+// IToolChoice實作 強制模型呼叫指定函數
 type ToolChoiceObject struct {
-	Type               string             `json:"type"`
-	ToolChoiceFunction ToolChoiceFunction `json:"function"`
+	Type               string             `json:"type"`     // 工具類型目前只有 "function"
+	ToolChoiceFunction ToolChoiceFunction `json:"function"` // 指定的函數
+}
+
+func (self ToolChoiceObject) Contents() string {
+	js, _ := json.Marshal(self)
+	return string(js)
 }
 
 type ToolChoiceFunction struct {
-	Name string `json:"name"`
+	Name string `json:"name"` // 函數名稱
 }
 
 ///////
@@ -95,7 +129,22 @@ type ToolChoiceFunction struct {
 type Tool struct {
 	Type         string       `json:"type"`     // 工具類型目前只有 "function"
 	ToolFunction ToolFunction `json:"function"` // 函數定義
-	Strict       bool         `json:"strict"`   // 生成函數呼叫時是否啟用嚴格的架構遵循。當 strict 為 true 時，僅支援 JSON 模式的子集。
+
+	// Deprecated: 請改用 ToolFunction.Strict, 送出時會併入 function.strict
+	Strict bool `json:"-"`
+}
+
+// 是否啟用嚴格的架構遵循, ToolFunction.Strict 或舊的 Strict 欄位其一為 true 即啟用
+func (self Tool) IsStrict() bool {
+	return self.Strict || self.ToolFunction.Strict
+}
+
+// 將舊的 Strict 欄位併入 function.strict
+func (self Tool) MarshalJSON() ([]byte, error) {
+	type alias Tool
+	tool := alias(self)
+	tool.ToolFunction.Strict = self.IsStrict()
+	return json.Marshal(tool)
 }
 
 // ToolFunction 定義工具的函數結構
@@ -103,28 +152,94 @@ type ToolFunction struct {
 	Name        string             `json:"name"`        // 函數名稱
 	Description string             `json:"description"` // 函數描述
 	Parameters  FunctionParameters `json:"parameters"`  // 函數參數
+	Strict      bool               `json:"strict"`      // 生成函數呼叫時是否啟用嚴格的架構遵循。當 strict 為 true 時，僅支援 JSON 模式的子集。
 }
 
-// FunctionParameters 定義函數參數的結構
-type FunctionParameters struct {
-	Type                 string               `json:"type"`                           // 函數參數類型
-	Properties           map[string]Parameter `json:"properties"`                     // 參數目錄表
-	Required             []string             `json:"required"`                       // 必要的參數名稱
-	AdditionalProperties bool                 `json:"additionalProperties,omitempty"` // 是否比對為定義參數
-}
+// FunctionParameters 定義函數參數的結構, 根節點固定為 "object"
+type FunctionParameters = Schema
 
 // Parameter 定義函數參數的具體屬性
-type Parameter struct {
-	Type        string   `json:"type"`           // 資料類型
-	Description string   `json:"description"`    // 資料描述
-	Enum        []string `json:"enum,omitempty"` // 資料限定序列
+type Parameter = Schema
+
+// JSON Schema 定義, 可遞迴描述巢狀物件與陣列
+//
+// 用於工具參數 FunctionParameters 與結構化輸出 ResponseFormatJSONSchema
+// strict 模式僅支援部分功能, 送出前可用 ValidateStrict 檢查
+type Schema struct {
+	Ref                  string            `json:"$ref,omitempty"`                 // 參照定義 EX: "#/$defs/Node", "#" 表示根節點
+	Defs                 map[string]Schema `json:"$defs,omitempty"`                // 可供 $ref 參照的定義, 只出現在根節點
+	Type                 string            `json:"type,omitempty"`                 // 資料類型, 使用 AnyOf 或 Ref 時為空
+	Description          string            `json:"description,omitempty"`          // 資料描述
+	Enum                 []string          `json:"enum,omitempty"`                 // 資料限定序列, 送出時依 Type 轉為對應的 JSON 值, 參考 EnumValues
+	Default              interface{}       `json:"default,omitempty"`              // 預設值, strict 模式不支援
+	Format               string            `json:"format,omitempty"`               // 字串格式 EX: "date-time", "email", "uuid"
+	Pattern              string            `json:"pattern,omitempty"`              // 字串需符合的正規表示式
+	Minimum              *float64          `json:"minimum,omitempty"`              // 數值下限
+	Maximum              *float64          `json:"maximum,omitempty"`              // 數值上限
+	MinItems             *int              `json:"minItems,omitempty"`             // 陣列元素數量下限
+	MaxItems             *int              `json:"maxItems,omitempty"`             // 陣列元素數量上限
+	Items                *Schema           `json:"items,omitempty"`                // 陣列元素定義, Type 為 "array" 時使用
+	Properties           map[string]Schema `json:"properties,omitempty"`           // 物件欄位定義, Type 為 "object" 時使用
+	Required             []string          `json:"required,omitempty"`             // 物件必要欄位名稱
+	AdditionalProperties bool              `json:"additionalProperties,omitempty"` // 物件是否允許未定義欄位, strict 模式必須為 false
+	AnyOf                []Schema          `json:"anyOf,omitempty"`                // 符合其中之一的定義, 用於可為 null 的欄位
 }
 
 // Usage 定義使用情況的結構體
 type Usage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens            int                     `json:"prompt_tokens"`
+	CompletionTokens        int                     `json:"completion_tokens"`
+	TotalTokens             int                     `json:"total_tokens"`
+	PromptTokensDetails     PromptTokensDetails     `json:"prompt_tokens_details"`     // 輸入 token 細項
+	CompletionTokensDetails CompletionTokensDetails `json:"completion_tokens_details"` // 輸出 token 細項
+}
+
+// 累加另一筆使用紀錄
+func (self *Usage) Add(other Usage) {
+	self.PromptTokens += other.PromptTokens
+	self.CompletionTokens += other.CompletionTokens
+	self.TotalTokens += other.TotalTokens
+	self.PromptTokensDetails.CachedTokens += other.PromptTokensDetails.CachedTokens
+	self.PromptTokensDetails.AudioTokens += other.PromptTokensDetails.AudioTokens
+	self.CompletionTokensDetails.ReasoningTokens += other.CompletionTokensDetails.ReasoningTokens
+	self.CompletionTokensDetails.AudioTokens += other.CompletionTokensDetails.AudioTokens
+	self.CompletionTokensDetails.AcceptedPredictionTokens += other.CompletionTokensDetails.AcceptedPredictionTokens
+	self.CompletionTokensDetails.RejectedPredictionTokens += other.CompletionTokensDetails.RejectedPredictionTokens
+}
+
+// 輸入 token 細項
+type PromptTokensDetails struct {
+	CachedTokens int `json:"cached_tokens"` // 命中提示快取的 token 數量
+	AudioTokens  int `json:"audio_tokens"`  // 音訊輸入 token 數量
+}
+
+// 輸出 token 細項
+type CompletionTokensDetails struct {
+	ReasoningTokens          int `json:"reasoning_tokens"`           // 推理模型內部思考使用的 token 數量
+	AudioTokens              int `json:"audio_tokens"`               // 音訊輸出 token 數量
+	AcceptedPredictionTokens int `json:"accepted_prediction_tokens"` // 預測輸出中被採用的 token 數量
+	RejectedPredictionTokens int `json:"rejected_prediction_tokens"` // 預測輸出中未被採用的 token 數量
+}
+
+// 輸出 token 對數機率
+type ChoiceLogprobs struct {
+	Content []TokenLogprob `json:"content"` // 內文 token
+	Refusal []TokenLogprob `json:"refusal"` // 拒絕回應 token
+}
+
+// 單一 token 的對數機率
+type TokenLogprob struct {
+	Token       string       `json:"token"`
+	Logprob     float64      `json:"logprob"`
+	Bytes       []int        `json:"bytes"`        // token 的 UTF-8 位元組
+	TopLogprobs []TopLogprob `json:"top_logprobs"` // 此位置機率最高的候選 token
+}
+
+// 候選 token 的對數機率
+type TopLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+	Bytes   []int   `json:"bytes"`
 }
 
 // 工具呼叫先關參數
@@ -139,9 +254,13 @@ type ToolCallsFunction struct {
 	Arguments string `json:"arguments"` // 輸入參數 json string
 }
 
-// IToolChoice實作 定義選擇的結構體
-type ToolChoice struct {
-	Message      AssistantMessage `json:"message"`       // 回應內容
-	FinishReason string           `json:"finish_reason"` // 完成原因
-	Index        int              `json:"index"`         // 索引值
+// 模型回應的單一選擇
+type Choice struct {
+	Message      AssistantMessage `json:"message"`            // 回應內容
+	FinishReason string           `json:"finish_reason"`      // 完成原因
+	Index        int              `json:"index"`              // 索引值
+	Logprobs     *ChoiceLogprobs  `json:"logprobs,omitempty"` // 輸出 token 對數機率, 需開啟 Logprobs
 }
+
+// Deprecated: 此型別為模型回應的選擇而非工具選擇, 請改用 Choice
+type ToolChoice = Choice
@@ -13,31 +13,75 @@ type ErrorDetail struct {
 }
 
 // Completions Request 請求結構
-type completionsRequest struct {
+//
+// 可透過 NewCompletionsRequest 建立後以 With* 方法串接設定, 送出前以 Validate 檢查
+type ChatCompletionRequest struct {
 	Model      string      `json:"model"`
-	Messages   []IMessage  `json:"messages"`
-	MaxTokens  int         `json:"max_tokens"`            // 最大 token 使用數量 (每個token大約能回傳4的文字的內文)
+	Messages   Messages    `json:"messages"`
+	MaxTokens  int         `json:"max_tokens,omitempty"`  // 最大 token 使用數量 (每個token大約能回傳4的文字的內文)
 	Tools      []Tool      `json:"tools,omitempty"`       // 模型可能呼叫的工具列表。目前，僅支援函數。使用它來提供模型可以為其產生 JSON 輸入的函數列表。最多支援 128 個功能。
 	ToolChoice IToolChoice `json:"tool_choice,omitempty"` //
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"` // 指定模型輸出格式
+
+	Stream        bool           `json:"stream,omitempty"`         // 是否以串流 (server-sent events) 方式回應
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"` // 串流回應選項, 僅在 Stream 為 true 時有效
+
+	Temperature         *float64          `json:"temperature,omitempty"`           // 取樣溫度 0~2, 越高輸出越隨機
+	TopP                *float64          `json:"top_p,omitempty"`                 // 核取樣機率 0~1, 建議與 Temperature 擇一調整
+	N                   int               `json:"n,omitempty"`                     // 每個輸入產生的回應數量
+	Stop                []string          `json:"stop,omitempty"`                  // 停止序列, 最多 4 個
+	PresencePenalty     *float64          `json:"presence_penalty,omitempty"`      // 存在懲罰 -2~2, 正值鼓勵談論新主題
+	FrequencyPenalty    *float64          `json:"frequency_penalty,omitempty"`     // 頻率懲罰 -2~2, 正值降低重複內容
+	LogitBias           map[string]int    `json:"logit_bias,omitempty"`            // 指定 token id 出現機率的偏差值 -100~100
+	Seed                *int              `json:"seed,omitempty"`                  // 取樣種子, 盡可能產生可重現的結果
+	User                string            `json:"user,omitempty"`                  // 終端使用者識別, 協助 OpenAI 監控濫用
+	Logprobs            bool              `json:"logprobs,omitempty"`              // 是否回傳輸出 token 的對數機率
+	TopLogprobs         *int              `json:"top_logprobs,omitempty"`          // 每個位置回傳機率最高的 token 數量 0~20, 需開啟 Logprobs
+	ParallelToolCalls   *bool             `json:"parallel_tool_calls,omitempty"`   // 是否允許同時呼叫多個工具
+	MaxCompletionTokens int               `json:"max_completion_tokens,omitempty"` // 輸出 token 上限 (含推理 token), 取代 MaxTokens
+	ReasoningEffort     string            `json:"reasoning_effort,omitempty"`      // 推理模型思考程度 EX: "low", "medium", "high"
+	ServiceTier         string            `json:"service_tier,omitempty"`          // 服務層級 EX: "auto", "default", "flex"
+	Metadata            map[string]string `json:"metadata,omitempty"`              // 自訂標籤, 需搭配 Store
+	Store               *bool             `json:"store,omitempty"`                 // 是否儲存本次輸出供模型蒸餾或評估
+}
+
+// 模型輸出格式
+type ResponseFormat struct {
+	Type       string                    `json:"type"`                  // 格式類型 EX: ResponseFormatType_Text, ResponseFormatType_JSONSchema
+	JSONSchema *ResponseFormatJSONSchema `json:"json_schema,omitempty"` // 結構化輸出 schema, 僅在 ResponseFormatType_JSONSchema 時使用
+}
+
+// 結構化輸出 schema 定義
+type ResponseFormatJSONSchema struct {
+	Name        string  `json:"name"`                  // schema 名稱 a-z, A-Z, 0-9, _ , - 最長 64 字
+	Description string  `json:"description,omitempty"` // schema 說明, 協助模型理解輸出用途
+	Schema      *Schema `json:"schema"`                // JSON Schema 內容
+	Strict      bool    `json:"strict"`                // 是否嚴格遵循 schema, 為 true 時僅支援 JSON Schema 子集
+}
+
+// 串流回應選項
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"` // 是否在最後一個片段附帶 token 使用紀錄
 }
 
 // Completions Response 回應結構
 type CompletionsResponse struct {
-	ID                 string       `json:"id"`
-	Object             string       `json:"object"`
-	Created            int          `json:"created"` // 完成時間
-	Model              string       `json:"model"`   // 本次請求指定模型
-	Usage              Usage        `json:"usage"`   // token 使用紀錄
-	Choices            []ToolChoice `json:"choices"` // 模型完成後返回的清單
-	Service_tier       string       `json:"service_tier,omitempty"`
-	System_fingerprint string       `json:"system_fingerprint"`
+	ID                 string   `json:"id"`
+	Object             string   `json:"object"`
+	Created            int      `json:"created"` // 完成時間
+	Model              string   `json:"model"`   // 本次請求指定模型
+	Usage              Usage    `json:"usage"`   // token 使用紀錄
+	Choices            []Choice `json:"choices"` // 模型完成後返回的清單
+	Service_tier       string   `json:"service_tier,omitempty"`
+	System_fingerprint string   `json:"system_fingerprint"`
 }
 
-func (self *completionsRequest) AddMessage(message IMessage) {
+func (self *ChatCompletionRequest) AddMessage(message IMessage) {
 	self.Messages = append(self.Messages, message)
 }
 
-func (self *completionsRequest) AddTools(tools []Tool) {
+func (self *ChatCompletionRequest) AddTools(tools []Tool) {
 	self.Tools = append(self.Tools, tools...)
 }
 
@@ -88,6 +132,56 @@ type DeleteFileResponse struct {
 	Deleted bool   `json:"deleted"` // 是否刪除
 }
 
+// Embeddings Request 請求結構
+type EmbeddingsRequest struct {
+	Model          string      `json:"model"`
+	Input          interface{} `json:"input"`                     // 輸入內容, 可為 string, []string, token 陣列 []int 或 [][]int
+	Dimensions     int         `json:"dimensions,omitempty"`      // 輸出向量維度, 僅 text-embedding-3 以後的模型支援
+	EncodingFormat string      `json:"encoding_format,omitempty"` // 向量格式 "float" 或 "base64"
+	User           string      `json:"user,omitempty"`            // 終端使用者識別
+}
+
+// Embeddings 回應
+type EmbeddingsResponse struct {
+	Object string      `json:"object"` // 固定為 "list"
+	Data   []Embedding `json:"data"`   // 各輸入的向量, 依 Index 對應輸入順序
+	Model  string      `json:"model"`  // 本次請求指定模型
+	Usage  Usage       `json:"usage"`  // token 使用紀錄, 只有 PromptTokens 與 TotalTokens
+}
+
+// 單一輸入的向量
+type Embedding struct {
+	Object    string    `json:"object"`    // 固定為 "embedding"
+	Index     int       `json:"index"`     // 對應的輸入索引
+	Embedding []float32 `json:"embedding"` // 向量, base64 格式回應時已解碼
+}
+
+// 模型資訊
+type ModelObject struct {
+	ID      string `json:"id"`       // 模型名稱
+	Object  string `json:"object"`   // 固定為 "model"
+	Created int    `json:"created"`  // 建立時間
+	OwnedBy string `json:"owned_by"` // 模型擁有者
+}
+
+// 模型列表回應
+type ListModelsResponse struct {
+	Object string        `json:"object"` // 固定為 "list"
+	Data   []ModelObject `json:"data"`   // 模型列表
+}
+
+// 模型查詢回應
+type RetrieveModelResponse struct {
+	ModelObject
+}
+
+// 刪除模型回應
+type DeleteModelResponse struct {
+	ID      string `json:"id"`      // 模型名稱
+	Object  string `json:"object"`  // 物件類型
+	Deleted bool   `json:"deleted"` // 是否刪除
+}
+
 type RetrieveFileContentResponse struct {
 	Data []BatchOutput // 每列資料
 }
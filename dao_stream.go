@@ -0,0 +1,39 @@
+package gptapi
+
+// 串流回應片段
+type ChatCompletionChunk struct {
+	ID                string        `json:"id"`
+	Object            string        `json:"object"`          // 固定為 "chat.completion.chunk"
+	Created           int           `json:"created"`         // 建立時間
+	Model             string        `json:"model"`           // 本次請求指定模型
+	Choices           []ChunkChoice `json:"choices"`         // 本片段的增量內容, 附帶 usage 的最後一個片段為空
+	Usage             *Usage        `json:"usage,omitempty"` // token 使用紀錄, 僅在 stream_options.include_usage 時於最後一個片段提供
+	ServiceTier       string        `json:"service_tier,omitempty"`
+	SystemFingerprint string        `json:"system_fingerprint"`
+}
+
+// 串流片段中的單一選項
+type ChunkChoice struct {
+	Index        int             `json:"index"`              // 索引值
+	Delta        ChunkDelta      `json:"delta"`              // 增量內容
+	FinishReason string          `json:"finish_reason"`      // 完成原因, 未完成時為空字串
+	Logprobs     *ChoiceLogprobs `json:"logprobs,omitempty"` // 本片段 token 對數機率
+}
+
+// 串流增量內容
+type ChunkDelta struct {
+	Role      string          `json:"role,omitempty"`       // 訊息來源角色, 只出現在第一個片段
+	Content   string          `json:"content,omitempty"`    // 內文片段
+	Refusal   string          `json:"refusal,omitempty"`    // 拒絕回應原因片段
+	ToolCalls []ToolCallDelta `json:"tool_calls,omitempty"` // tool 調用片段
+}
+
+// tool 調用片段
+//
+// 同一個 tool 調用的 ID/Name 只出現在第一個片段, 後續片段以 Index 對應並接續 Arguments
+type ToolCallDelta struct {
+	Index    int               `json:"index"`          // tool 調用索引
+	ID       string            `json:"id,omitempty"`   // tool 調用Id
+	Type     string            `json:"type,omitempty"` // 工具類型目前只有 "function"
+	Function ToolCallsFunction `json:"function"`       // 方法名稱與參數片段
+}
@@ -0,0 +1,226 @@
+package gptapi
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// 檢查 Embeddings 請求內容
+//
+// 回傳的錯誤包含所有不合法的欄位, 可用 errors.Join 的方式逐一檢視
+func (self *EmbeddingsRequest) Validate() error {
+	var errs []error
+
+	if self.Model == "" {
+		errs = append(errs, errors.New("[Validate] Error model is empty"))
+	}
+
+	switch input := self.Input.(type) {
+	case string:
+		if input == "" {
+			errs = append(errs, errors.New("[Validate] Error input is empty"))
+		}
+	case []string:
+		errs = append(errs, validateEmbeddingInputs(len(input), func(i int) bool { return input[i] == "" })...)
+	case []int:
+		if len(input) == 0 {
+			errs = append(errs, errors.New("[Validate] Error input is empty"))
+		}
+	case [][]int:
+		errs = append(errs, validateEmbeddingInputs(len(input), func(i int) bool { return len(input[i]) == 0 })...)
+	case nil:
+		errs = append(errs, errors.New("[Validate] Error input is empty"))
+	default:
+		errs = append(errs, fmt.Errorf("[Validate] Error invalid input type: %T", self.Input))
+	}
+
+	if self.Dimensions < 0 {
+		errs = append(errs, fmt.Errorf("[Validate] Error invalid dimensions: %d", self.Dimensions))
+	}
+	switch self.EncodingFormat {
+	case "", EmbeddingEncoding_Float, EmbeddingEncoding_Base64:
+	default:
+		errs = append(errs, fmt.Errorf("[Validate] Error invalid encoding_format: %q", self.EncodingFormat))
+	}
+
+	return errors.Join(errs...)
+}
+
+// 檢查多筆輸入的數量與內容
+func validateEmbeddingInputs(count int, empty func(i int) bool) []error {
+	var errs []error
+	if count == 0 {
+		errs = append(errs, errors.New("[Validate] Error input is empty"))
+	}
+	if count > EmbeddingInputsLimit {
+		errs = append(errs, fmt.Errorf("[Validate] Error too many inputs: %d, limit: %d", count, EmbeddingInputsLimit))
+	}
+	for i := 0; i < count; i++ {
+		if empty(i) {
+			errs = append(errs, fmt.Errorf("[Validate] Error input[%d] is empty", i))
+		}
+	}
+	return errs
+}
+
+// 解析向量, embedding 欄位可為浮點數陣列或 base64 編碼的 little-endian float32
+func (self *Embedding) UnmarshalJSON(data []byte) error {
+	type alias Embedding
+	aux := struct {
+		*alias
+		Embedding json.RawMessage `json:"embedding"`
+	}{alias: (*alias)(self)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	self.Embedding = nil
+	if len(aux.Embedding) == 0 || string(aux.Embedding) == "null" {
+		return nil
+	}
+	if aux.Embedding[0] != '"' {
+		return json.Unmarshal(aux.Embedding, &self.Embedding)
+	}
+
+	var encoded string
+	if err := json.Unmarshal(aux.Embedding, &encoded); err != nil {
+		return err
+	}
+	vector, err := DecodeEmbeddingBase64(encoded)
+	if err != nil {
+		return err
+	}
+	self.Embedding = vector
+	return nil
+}
+
+// 解碼 base64 格式的向量
+func DecodeEmbeddingBase64(encoded string) ([]float32, error) {
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("[DecodeEmbeddingBase64] Error decode: %v", err)
+	}
+	if len(data)%4 != 0 {
+		return nil, fmt.Errorf("[DecodeEmbeddingBase64] Error invalid length: %d", len(data))
+	}
+
+	vector := make([]float32, len(data)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[i*4:]))
+	}
+	return vector, nil
+}
+
+// 依輸入順序取得所有向量
+func (self *EmbeddingsResponse) Vectors() [][]float32 {
+	vectors := make([][]float32, len(self.Data))
+	for _, data := range self.Data {
+		if 0 <= data.Index && data.Index < len(vectors) {
+			vectors[data.Index] = data.Embedding
+		}
+	}
+	return vectors
+}
+
+// 未註冊詞彙表時 token 估算值額外加上的百分比, 避免低估造成請求超過上限
+const embeddingEstimateMargin = 25
+
+// 大量輸入的文字向量, 依 token 上限切分為多個請求後合併結果
+func (self *Client) EmbeddingsChunked(reqBody EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	return self.EmbeddingsChunkedContext(context.Background(), reqBody)
+}
+
+// 大量輸入的文字向量, 以 ctx 控制取消與逾時
+//
+// Input 需為 []string 或 [][]int, 每個請求不超過 EmbeddingInputsLimit 筆且合計不超過 EmbeddingRequestTokensLimit,
+// 單一輸入超過 EmbeddingInputTokensLimit 時回傳錯誤
+// 文字的 token 數量在已註冊詞彙表 (RegisterEncoding) 時精確計算, 否則為近似值:
+// 以估算值加上 embeddingEstimateMargin 的安全餘量切分, 單一輸入的上限交由 API 檢查
+// 合併後的 Index 對應原始輸入順序, Usage 為所有請求的合計
+func (self *Client) EmbeddingsChunkedContext(ctx context.Context, reqBody EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	if reqBody.Model == "" {
+		reqBody.Model = DefaultEmbeddingModel
+	}
+
+	var chunks []interface{}
+	var err error
+	switch input := reqBody.Input.(type) {
+	case []string:
+		counter, exact := textCounterFor(reqBody.Model)
+		chunks, err = chunkEmbeddingInputs(input, exact, func(i int) int { return counter(input[i]) })
+	case [][]int:
+		chunks, err = chunkEmbeddingInputs(input, true, func(i int) int { return len(input[i]) })
+	default:
+		return nil, fmt.Errorf("[EmbeddingsChunked] Error input must be []string or [][]int, got: %T", reqBody.Input)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, errors.New("[EmbeddingsChunked] Error input is empty")
+	}
+
+	merged := &EmbeddingsResponse{
+		Object: "list",
+		Model:  reqBody.Model,
+	}
+	offset := 0
+	for _, chunk := range chunks {
+		chunkReq := reqBody
+		chunkReq.Input = chunk
+
+		response, err := self.EmbeddingsContext(ctx, chunkReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, data := range response.Data {
+			data.Index += offset
+			merged.Data = append(merged.Data, data)
+		}
+		if response.Model != "" {
+			merged.Model = response.Model
+		}
+		merged.Usage.Add(response.Usage)
+		offset += embeddingInputCount(chunk)
+	}
+
+	return merged, nil
+}
+
+// 依數量與 token 上限切分輸入, exact 為 false 時 token 數量為估算值, 加上安全餘量且不檢查單一輸入上限
+func chunkEmbeddingInputs[T any](inputs []T, exact bool, tokens func(i int) int) ([]interface{}, error) {
+	var chunks []interface{}
+	start, total := 0, 0
+	for i := range inputs {
+		count := tokens(i)
+		if !exact {
+			count += count * embeddingEstimateMargin / 100
+		} else if count > EmbeddingInputTokensLimit {
+			return nil, fmt.Errorf("[EmbeddingsChunked] Error input[%d] has %d tokens, limit: %d", i, count, EmbeddingInputTokensLimit)
+		}
+		if i > start && (i-start >= EmbeddingInputsLimit || total+count > EmbeddingRequestTokensLimit) {
+			chunks = append(chunks, inputs[start:i])
+			start, total = i, 0
+		}
+		total += count
+	}
+	if start < len(inputs) {
+		chunks = append(chunks, inputs[start:])
+	}
+	return chunks, nil
+}
+
+func embeddingInputCount(input interface{}) int {
+	switch in := input.(type) {
+	case []string:
+		return len(in)
+	case [][]int:
+		return len(in)
+	}
+	return 1
+}
@@ -0,0 +1,194 @@
+package gptapi
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// 將向量編碼為 little-endian float32 的 base64 字串
+func encodeEmbeddingBase64(vector []float32) string {
+	data := make([]byte, len(vector)*4)
+	for i, value := range vector {
+		binary.LittleEndian.PutUint32(data[i*4:], math.Float32bits(value))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
+
+func TestEmbeddingUnmarshalJSON(t *testing.T) {
+	vector := []float32{0.5, -1.25, 3.0e-7, 0}
+	tests := []struct {
+		name    string
+		js      string
+		want    []float32
+		wantErr bool
+	}{
+		{"float", `{"object":"embedding","index":1,"embedding":[0.5,-1.25,3e-7,0]}`, vector, false},
+		{"base64", `{"object":"embedding","index":1,"embedding":"` + encodeEmbeddingBase64(vector) + `"}`, vector, false},
+		{"empty base64", `{"object":"embedding","index":1,"embedding":""}`, []float32{}, false},
+		{"null", `{"object":"embedding","index":1,"embedding":null}`, nil, false},
+		{"missing", `{"object":"embedding","index":1}`, nil, false},
+		{"invalid base64", `{"object":"embedding","index":1,"embedding":"!!!"}`, nil, true},
+		{"invalid length", `{"object":"embedding","index":1,"embedding":"AAAA"}`, nil, true},
+		{"invalid type", `{"object":"embedding","index":1,"embedding":{}}`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			embedding := Embedding{Embedding: []float32{9}}
+			err := json.Unmarshal([]byte(tt.js), &embedding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if embedding.Object != "embedding" || embedding.Index != 1 {
+				t.Errorf("header = %q %d, want embedding 1", embedding.Object, embedding.Index)
+			}
+			if !reflect.DeepEqual(embedding.Embedding, tt.want) {
+				t.Errorf("embedding = %v, want %v", embedding.Embedding, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunkEmbeddingInputs(t *testing.T) {
+	tests := []struct {
+		name    string
+		count   int
+		tokens  int // 每筆輸入的 token 數量
+		exact   bool
+		want    []int // 各請求的輸入數量
+		wantErr bool
+	}{
+		{"empty", 0, 1, true, nil, false},
+		{"single request", 10, 100, true, []int{10}, false},
+		{"inputs limit", EmbeddingInputsLimit*2 + 1, 1, true, []int{EmbeddingInputsLimit, EmbeddingInputsLimit, 1}, false},
+		{"request tokens limit", 100, 8000, true, []int{37, 37, 26}, false},
+		{"exact request tokens", 41, 7500, true, []int{40, 1}, false},
+		{"input tokens limit", 1, EmbeddingInputTokensLimit, true, []int{1}, false},
+		{"input exceeds limit", 3, EmbeddingInputTokensLimit + 1, true, nil, true},
+		// 估算值加上 25% 餘量: 8000 -> 10000, 每個請求 30 筆
+		{"estimate margin", 100, 8000, false, []int{30, 30, 30, 10}, false},
+		// 估算值不檢查單一輸入上限
+		{"estimate over input limit", 2, EmbeddingInputTokensLimit + 1, false, []int{2}, false},
+		// 單一輸入超過請求上限時仍獨立成為一個請求
+		{"estimate over request limit", 2, EmbeddingRequestTokensLimit, false, []int{1, 1}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inputs := make([]string, tt.count)
+			for i := range inputs {
+				inputs[i] = fmt.Sprint(i)
+			}
+			chunks, err := chunkEmbeddingInputs(inputs, tt.exact, func(i int) int { return tt.tokens })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			var got []int
+			next := 0
+			for _, chunk := range chunks {
+				part := chunk.([]string)
+				if len(part) == 0 || part[0] != inputs[next] {
+					t.Fatalf("chunk starts at %v, want input %d", part, next)
+				}
+				got = append(got, len(part))
+				next += len(part)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunks = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmbeddingsChunked(t *testing.T) {
+	var requests []EmbeddingsRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		req := struct {
+			EmbeddingsRequest
+			Input [][]int `json:"input"`
+		}{}
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Errorf("request body: %v", err)
+		}
+		req.EmbeddingsRequest.Input = req.Input
+		requests = append(requests, req.EmbeddingsRequest)
+
+		// 以輸入的第一個 token 作為向量內容, 驗證合併後的順序
+		response := EmbeddingsResponse{Object: "list", Model: "text-embedding-3-small"}
+		data := []string{}
+		for i, input := range req.Input {
+			data = append(data, fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":"%s"}`, i, encodeEmbeddingBase64([]float32{float32(input[0])})))
+		}
+		response.Usage.PromptTokens = len(req.Input)
+		response.Usage.TotalTokens = len(req.Input)
+		usage, _ := json.Marshal(response.Usage)
+		fmt.Fprintf(w, `{"object":"list","model":"text-embedding-3-small","data":[%s],"usage":%s}`, strings.Join(data, ","), usage)
+	}))
+	defer server.Close()
+
+	inputs := make([][]int, EmbeddingInputsLimit+5)
+	for i := range inputs {
+		inputs[i] = []int{i}
+	}
+	client := NewClient(WithAPIKey("key"), WithBaseURL(server.URL))
+	response, err := client.EmbeddingsChunked(EmbeddingsRequest{Input: inputs, EncodingFormat: "base64"})
+	if err != nil {
+		t.Fatalf("EmbeddingsChunked: %v", err)
+	}
+
+	if len(requests) != 2 || len(requests[0].Input.([][]int)) != EmbeddingInputsLimit || len(requests[1].Input.([][]int)) != 5 {
+		t.Fatalf("requests = %d, want 2 with %d and 5 inputs", len(requests), EmbeddingInputsLimit)
+	}
+	for _, req := range requests {
+		if req.Model != DefaultEmbeddingModel || req.EncodingFormat != "base64" {
+			t.Errorf("request = %s %s, want %s base64", req.Model, req.EncodingFormat, DefaultEmbeddingModel)
+		}
+	}
+
+	if response.Usage.PromptTokens != len(inputs) || response.Usage.TotalTokens != len(inputs) {
+		t.Errorf("usage = %+v, want %d tokens", response.Usage, len(inputs))
+	}
+	vectors := response.Vectors()
+	if len(vectors) != len(inputs) {
+		t.Fatalf("vectors = %d, want %d", len(vectors), len(inputs))
+	}
+	for i, vector := range vectors {
+		if len(vector) != 1 || vector[0] != float32(i) {
+			t.Fatalf("vectors[%d] = %v, want [%d]", i, vector, i)
+		}
+	}
+}
+
+func TestCreateEmbeddingsBatch(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/batches") {
+			t.Errorf("path = %s, want /batches", r.URL.Path)
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_, _ = w.Write([]byte(`{"id":"batch_1","object":"batch"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(WithAPIKey("key"), WithBaseURL(server.URL))
+	if _, err := client.CreateEmbeddingsBatch("file-1"); err != nil {
+		t.Fatalf("CreateEmbeddingsBatch: %v", err)
+	}
+	if body["input_file_id"] != "file-1" || body["endpoint"] != BatchEndpoint_Embeddings {
+		t.Errorf("body = %v, want file-1 %s", body, BatchEndpoint_Embeddings)
+	}
+}
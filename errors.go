@@ -0,0 +1,191 @@
+package gptapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// API 回應非 200 時的錯誤
+//
+// 可透過 errors.As 取得, 或使用 IsRateLimit 等函式判斷錯誤種類
+type APIError struct {
+	HTTPStatusCode int           // http 狀態碼
+	ErrorDetail                  // API 回傳的錯誤內容
+	RequestID      string        // x-request-id 標頭, 回報問題時提供給 OpenAI
+	RetryAfter     time.Duration // 伺服器建議的重試等待時間, 未提供時為 0
+	Body           []byte        // 原始回應內文
+}
+
+func (self *APIError) Error() string {
+	if self.Message == "" {
+		return fmt.Sprintf("received non-200 response: %d %s", self.HTTPStatusCode, self.Body)
+	}
+
+	if self.Code != "" {
+		return fmt.Sprintf("openai api error: status %d, code %s: %s", self.HTTPStatusCode, self.Code, self.Message)
+	}
+	return fmt.Sprintf("openai api error: status %d: %s", self.HTTPStatusCode, self.Message)
+}
+
+// 由 http 回應建立 APIError
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		HTTPStatusCode: resp.StatusCode,
+		RequestID:      resp.Header.Get("x-request-id"),
+		RetryAfter:     parseRetryAfter(resp.Header),
+		Body:           body,
+	}
+
+	if apiErr.RetryAfter == 0 && resp.StatusCode == http.StatusTooManyRequests {
+		apiErr.RetryAfter = parseRateLimitReset(resp.Header)
+	}
+
+	errRes := ErrorResponse{}
+	if err := json.Unmarshal(body, &errRes); err == nil {
+		apiErr.ErrorDetail = errRes.Error
+	}
+
+	return apiErr
+}
+
+// 解析 Retry-After 相關標頭
+//
+// 支援 "retry-after-ms" (毫秒), "Retry-After" (秒數或 http 日期)
+func parseRetryAfter(header http.Header) time.Duration {
+	if ms := header.Get("retry-after-ms"); ms != "" {
+		if v, err := strconv.ParseFloat(ms, 64); err == nil && v > 0 {
+			return time.Duration(v * float64(time.Millisecond))
+		}
+	}
+
+	retryAfter := header.Get("Retry-After")
+	if retryAfter == "" {
+		return 0
+	}
+
+	if v, err := strconv.ParseFloat(retryAfter, 64); err == nil && v > 0 {
+		return time.Duration(v * float64(time.Second))
+	}
+
+	if t, err := http.ParseTime(retryAfter); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+
+	return 0
+}
+
+// 解析 x-ratelimit-reset-* 標頭, 回傳請求數與 token 數限制中較晚重置的時間
+//
+// 標頭格式為 duration 字串 EX: "1s", "6m0s", "20ms"
+func parseRateLimitReset(header http.Header) time.Duration {
+	var reset time.Duration
+	for _, key := range []string{"x-ratelimit-reset-requests", "x-ratelimit-reset-tokens"} {
+		value := header.Get(key)
+		if value == "" {
+			continue
+		}
+
+		if d, err := time.ParseDuration(value); err == nil && d > reset {
+			reset = d
+		}
+	}
+
+	return reset
+}
+
+// 取出錯誤鏈中的 APIError
+func asAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
+
+// 是否為請求頻率或額度限制錯誤 (429)
+func IsRateLimit(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.HTTPStatusCode == http.StatusTooManyRequests || apiErr.Code == "rate_limit_exceeded"
+}
+
+// 是否為驗證或權限錯誤 (401/403)
+func IsAuth(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.HTTPStatusCode == http.StatusUnauthorized ||
+		apiErr.HTTPStatusCode == http.StatusForbidden ||
+		apiErr.Code == "invalid_api_key"
+}
+
+// 是否為輸入內容超出模型上下文長度錯誤
+func IsContextLengthExceeded(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.Code == "context_length_exceeded"
+}
+
+// 是否為伺服器端錯誤 (5xx)
+func IsServerError(err error) bool {
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return false
+	}
+	return apiErr.HTTPStatusCode >= http.StatusInternalServerError
+}
+
+// 模型拒絕回應結構化輸出
+type RefusalError struct {
+	Refusal string // 模型提供的拒絕原因
+}
+
+func (self *RefusalError) Error() string {
+	return fmt.Sprintf("model refused to respond: %s", self.Refusal)
+}
+
+// 模型輸出與指定的結構不符
+type SchemaMismatchError struct {
+	Content string // 模型原始輸出
+	Err     error  // 解析錯誤
+}
+
+func (self *SchemaMismatchError) Error() string {
+	return fmt.Sprintf("model output does not match schema: %v", self.Err)
+}
+
+func (self *SchemaMismatchError) Unwrap() error {
+	return self.Err
+}
+
+// RunConversation 達到最多呼叫模型次數仍未結束
+var ErrMaxIterations = errors.New("conversation reached max iterations")
+
+// 模型使用的詞彙表尚未註冊, 無法精確計算 token, 可改用 EstimateTokens
+var ErrEncodingNotRegistered = errors.New("encoding not registered")
+
+// 工具處理函式回傳的錯誤
+type ToolCallError struct {
+	Name   string // 工具名稱
+	CallID string // tool 調用Id
+	Err    error  // 處理函式回傳的錯誤
+}
+
+func (self *ToolCallError) Error() string {
+	return fmt.Sprintf("tool %s (call %s) failed: %v", self.Name, self.CallID, self.Err)
+}
+
+func (self *ToolCallError) Unwrap() error {
+	return self.Err
+}
@@ -1,13 +1,11 @@
 package gptapi
 
 import (
-	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"io"
-	"log"
 	"mime/multipart"
 	"net/http"
 	"net/url"
@@ -17,311 +15,249 @@ import (
 	"strings"
 )
 
-const (
-	// gpt-4o vs gpt-4o-mini 模型 token 價值不同, mini 消耗更多 token 但價格更便宜 4o VS 4o-mini 價格大約為 1:5
-	model = "gpt-4o-mini"
-)
-
-func NewCompletionsRequest(maxToken int) completionsRequest {
-	return completionsRequest{
-		Model:     model,
+func NewCompletionsRequest(maxToken int) ChatCompletionRequest {
+	return ChatCompletionRequest{
+		Model:     DefaultModel,
 		MaxTokens: maxToken,
 	}
 }
 
 // 模型任務
-func CompletionsRequest(apiKey string, reqBody completionsRequest) (*CompletionsResponse, error) {
-	// Marshal the request body to JSON
-	reqData, err := json.Marshal(reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %v", err)
+func (self *Client) Completions(reqBody ChatCompletionRequest) (*CompletionsResponse, error) {
+	return self.CompletionsContext(context.Background(), reqBody)
+}
+
+// 模型任務, 以 ctx 控制取消與逾時
+func (self *Client) CompletionsContext(ctx context.Context, reqBody ChatCompletionRequest) (*CompletionsResponse, error) {
+	if reqBody.Model == "" {
+		reqBody.Model = self.model
+	}
+	reqBody.NormalizeForModel()
+	if err := reqBody.Validate(); err != nil {
+		return nil, err
 	}
+	self.warn(&reqBody)
 
-	// Create a new HTTP request
-	req, err := http.NewRequest(http.MethodPost, Url_Completions, bytes.NewBuffer(reqData))
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
+	response := CompletionsResponse{}
+	if err := self.doJSON(ctx, http.MethodPost, Path_Completions, reqBody, &response, true); err != nil {
+		return nil, err
 	}
 
-	// Set headers
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", apiKey))
+	return &response, nil
+}
 
-	// Send the request
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to send request: %v", err)
+// 模型任務 以串流方式回應, 回傳可逐一讀取片段的 Stream
+func (self *Client) CompletionsStream(reqBody ChatCompletionRequest) (*Stream, error) {
+	return self.CompletionsStreamContext(context.Background(), reqBody)
+}
+
+// 模型任務 以串流方式回應, 以 ctx 控制取消與逾時
+//
+// 回傳的 Stream 使用完畢後需呼叫 Close
+// 未設定 StreamOptions 時預設要求在最後一個片段附帶 Usage
+func (self *Client) CompletionsStreamContext(ctx context.Context, reqBody ChatCompletionRequest) (*Stream, error) {
+	if reqBody.Model == "" {
+		reqBody.Model = self.model
+	}
+	reqBody.Stream = true
+	if reqBody.StreamOptions == nil {
+		reqBody.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+	reqBody.NormalizeForModel()
+	if err := reqBody.Validate(); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
+	self.warn(&reqBody)
 
-	// Read the response body
-	respBody, err := io.ReadAll(resp.Body)
+	jsonData, err := json.Marshal(reqBody)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %v", err)
+		return nil, fmt.Errorf("Error marshalling request body: %v", err)
 	}
 
-	// Check if the response status is not OK
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("received non-200 response: %s", respBody)
+	// 創建 HTTP 請求
+	req, err := self.newRequest(ctx, http.MethodPost, Path_Completions, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, err
 	}
+	req.Header.Set("Accept", "text/event-stream")
 
-	var response CompletionsResponse
-	err = json.Unmarshal(respBody, &response)
+	// 發送請求並接收回應
+	resp, err := self.send(req, true)
 	if err != nil {
-		return nil, fmt.Errorf("無法解析回應: %v", err)
+		return nil, err
 	}
-	return &response, nil
+
+	return newStream(ctx, resp), nil
 }
 
 // 模型任務 以串流方式回應
-func CompletionsStreamingRequest(apiKey string, reqBody completionsRequest, output chan<- string) error {
-	jsonData, err := json.Marshal(reqBody)
+func (self *Client) CompletionsStreaming(reqBody ChatCompletionRequest, output chan<- ChatCompletionChunk) error {
+	return self.CompletionsStreamingContext(context.Background(), reqBody, output)
+}
+
+// 模型任務 以串流方式回應, 以 ctx 控制取消與逾時
+//
+// 依序將解析後的片段送入 output, 回傳時會關閉 output
+func (self *Client) CompletionsStreamingContext(ctx context.Context, reqBody ChatCompletionRequest, output chan<- ChatCompletionChunk) error {
+	defer close(output)
+
+	stream, err := self.CompletionsStreamContext(ctx, reqBody)
 	if err != nil {
-		return fmt.Errorf("Error marshalling request body: %v", err)
+		return err
 	}
+	defer stream.Close()
 
-	// 創建 HTTP 請求
-	req, err := http.NewRequest(http.MethodPost, Url_Completions, bytes.NewBuffer(jsonData))
-	if err != nil {
-		return fmt.Errorf("Error creating request: %v", err)
+	for stream.Next() {
+		select {
+		case output <- stream.Current():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
 
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-	req.Header.Set("Content-Type", "application/json")
+	return stream.Err()
+}
 
-	// 發送請求並接收回應
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return fmt.Errorf("Error sending request: %v", err)
-	}
-	defer resp.Body.Close()
+// ///// 文字向量任務
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("Non-OK HTTP status: %v", resp.Status)
-	}
+// 文字向量
+func (self *Client) Embeddings(reqBody EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	return self.EmbeddingsContext(context.Background(), reqBody)
+}
 
-	// 讀取並處理流式數據
-	scanner := bufio.NewScanner(resp.Body)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if len(line) > 0 {
-			output <- line
-		}
+// 文字向量, 以 ctx 控制取消與逾時
+//
+// 未指定模型時使用 DefaultEmbeddingModel, 輸入數量超過單一請求上限時改用 EmbeddingsChunked
+func (self *Client) EmbeddingsContext(ctx context.Context, reqBody EmbeddingsRequest) (*EmbeddingsResponse, error) {
+	if reqBody.Model == "" {
+		reqBody.Model = DefaultEmbeddingModel
+	}
+	if err := reqBody.Validate(); err != nil {
+		return nil, err
 	}
 
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("Error reading response body: %v", err)
+	response := EmbeddingsResponse{}
+	if err := self.doJSON(ctx, http.MethodPost, Path_Embeddings, reqBody, &response, true); err != nil {
+		return nil, err
 	}
 
-	close(output)
-	return nil
+	return &response, nil
 }
 
 // ///// 批次任務
 
 // 建立新批次處理
-func CreateBatchRequest(apiKey, inputFileId string) (*CreateBatchResponse, error) {
-	requestBody := BatchRequest{
-		InputFileID:      inputFileId, // Replace with your actual file ID
-		Endpoint:         "/v1/chat/completions",
-		CompletionWindow: "24h",
-	}
+func (self *Client) CreateBatch(inputFileId string) (*CreateBatchResponse, error) {
+	return self.CreateBatchContext(context.Background(), inputFileId)
+}
 
-	jsonData, err := json.Marshal(requestBody)
-	if err != nil {
-		log.Fatalf("Error marshaling request data: %v", err)
-	}
+// 建立新批次處理, 以 ctx 控制取消與逾時
+func (self *Client) CreateBatchContext(ctx context.Context, inputFileId string) (*CreateBatchResponse, error) {
+	return self.createBatch(ctx, inputFileId, BatchEndpoint_Completions)
+}
 
-	req, err := http.NewRequest(http.MethodPost, Url_Batches, bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.Fatalf("Error creating request: %v", err)
-	}
+// 建立文字向量批次處理, 檔案由 NewEmbeddingsJsonlFile 寫入
+func (self *Client) CreateEmbeddingsBatch(inputFileId string) (*CreateBatchResponse, error) {
+	return self.CreateEmbeddingsBatchContext(context.Background(), inputFileId)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+// 建立文字向量批次處理, 以 ctx 控制取消與逾時
+//
+// 結果以 RetrieveFileContent 搭配 BatchType_Embeddings 解析
+func (self *Client) CreateEmbeddingsBatchContext(ctx context.Context, inputFileId string) (*CreateBatchResponse, error) {
+	return self.createBatch(ctx, inputFileId, BatchEndpoint_Embeddings)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		log.Fatalf("Error sending request: %v", err)
+// 建立批次處理, endpoint 需與檔案中每筆請求的 url 相同
+func (self *Client) createBatch(ctx context.Context, inputFileId, endpoint string) (*CreateBatchResponse, error) {
+	requestBody := BatchRequest{
+		InputFileID:      inputFileId,
+		Endpoint:         endpoint,
+		CompletionWindow: "24h",
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Fatalf("Error reading response body: %v", err)
+	// 建立批次會產生新任務, 重送可能造成重複建立
+	res := CreateBatchResponse{}
+	if err := self.doJSON(ctx, http.MethodPost, Path_Batches, requestBody, &res, false); err != nil {
+		return nil, err
 	}
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
 
-		return nil, errors.New(errRes.Error.Message)
-
-	} else {
-		res := CreateBatchResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
-		}
-
-		return &res, nil
-	}
+	return &res, nil
 }
 
 // 取得已存在批次任務
+func (self *Client) ListBatch(after string, limit int) (*ListBatchResponse, error) {
+	return self.ListBatchContext(context.Background(), after, limit)
+}
+
+// 取得已存在批次任務, 以 ctx 控制取消與逾時
 //
 // @after 指定batchId 查詢會從此ID後的像是開始輸出
 // @limit 指定輸出項目數量 default: 20 range: 1~100
-func ListBatchRequest(apiKey, after string, limit int) (*ListBatchResponse, error) {
+func (self *Client) ListBatchContext(ctx context.Context, after string, limit int) (*ListBatchResponse, error) {
 
 	if limit < 1 || 100 < limit {
 		limit = 20
 	}
 
-	apiUrl, err := url.Parse(Url_ListBatch)
-	if err != nil {
-		return nil, err
-	}
-
 	// 創建查詢參數
 	params := url.Values{}
 	if after != "" {
-		params.Add("after", "batch_10") // 添加 `after` 參數
+		params.Add("after", after) // 添加 `after` 參數
 	}
 	params.Add("limit", strconv.Itoa(limit)) // 添加 `limit` 參數
 
-	// 設置查詢參數到 URL 中
-	apiUrl.RawQuery = params.Encode()
-	req, err := http.NewRequest(http.MethodGet, apiUrl.String(), nil)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
-
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+	res := ListBatchResponse{}
+	if err := self.doJSON(ctx, http.MethodGet, Path_ListBatch+"?"+params.Encode(), nil, &res, true); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(errRes.Error.Message)
 
-	} else {
-		res := ListBatchResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
-		}
-
-		return &res, nil
-	}
+	return &res, nil
 }
 
 // 查詢指定批次任務
-func RetrieveBatchRequest(apiKey, batchId string) (*RetrieveBatchResponse, error) {
-	url := strings.Replace(Url_RetrieveBatch, "{batch_id}", batchId, -1)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
+func (self *Client) RetrieveBatch(batchId string) (*RetrieveBatchResponse, error) {
+	return self.RetrieveBatchContext(context.Background(), batchId)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+// 查詢指定批次任務, 以 ctx 控制取消與逾時
+func (self *Client) RetrieveBatchContext(ctx context.Context, batchId string) (*RetrieveBatchResponse, error) {
+	path := strings.ReplaceAll(Path_RetrieveBatch, "{batch_id}", batchId)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+	res := RetrieveBatchResponse{}
+	if err := self.doJSON(ctx, http.MethodGet, path, nil, &res, true); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(errRes.Error.Message)
-
-	} else {
-		res := RetrieveBatchResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
-		}
 
-		return &res, nil
-	}
+	return &res, nil
 }
 
 // 取消指定批次任務
-func CancelBatchRequest(apiKey, batchId string) (*CancelBatchResponse, error) {
-	url := strings.ReplaceAll(Url_CancelBatch, "{batch_id}", batchId)
-	req, err := http.NewRequest(http.MethodPost, url, nil)
-	if err != nil {
-		return nil, err
-	}
+func (self *Client) CancelBatch(batchId string) (*CancelBatchResponse, error) {
+	return self.CancelBatchContext(context.Background(), batchId)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+// 取消指定批次任務, 以 ctx 控制取消與逾時
+func (self *Client) CancelBatchContext(ctx context.Context, batchId string) (*CancelBatchResponse, error) {
+	path := strings.ReplaceAll(Path_CancelBatch, "{batch_id}", batchId)
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+	res := CancelBatchResponse{}
+	if err := self.doJSON(ctx, http.MethodPost, path, nil, &res, true); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(errRes.Error.Message)
-
-	} else {
-		res := CancelBatchResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
-		}
-
-		return &res, nil
-	}
+	return &res, nil
 }
 
 // ///// 檔案處理任務
 
 // 上傳檔案
-func UploadFileRequest(apiKey, jsonlPath, purpose string) (*FileUploadResponse, error) {
+func (self *Client) UploadFile(jsonlPath, purpose string) (*FileUploadResponse, error) {
+	return self.UploadFileContext(context.Background(), jsonlPath, purpose)
+}
 
-	// apiKey := "YOUR_OPENAI_API_KEY"        // 替换为你的 OpenAI API 金钥
-	// filePath := "path/to/your/file.jsonl"  // 替换为你要上传的文件路径
-	// purpose := "fine-tune"                  // 替换为文件的用途
+// 上傳檔案, 以 ctx 控制取消與逾時
+func (self *Client) UploadFileContext(ctx context.Context, jsonlPath, purpose string) (*FileUploadResponse, error) {
 
 	// 打开文件
 	file, err := os.Open(jsonlPath)
@@ -334,7 +270,7 @@ func UploadFileRequest(apiKey, jsonlPath, purpose string) (*FileUploadResponse,
 	if err != nil {
 		return nil, fmt.Errorf("檔案狀態異常: %v", err)
 	} else if fs.Size() >= BatchFileSizeLimit { // 檔案大小檢查
-		return nil, fmt.Errorf("檔案過大: %v", err)
+		return nil, fmt.Errorf("檔案過大: %d", fs.Size())
 	} else if filepath.Ext(fs.Name()) != ".jsonl" { // 只支援 .jsonl格式
 		return nil, fmt.Errorf("[UploadFile] Error filetype filePath: %s", jsonlPath)
 	}
@@ -361,24 +297,16 @@ func UploadFileRequest(apiKey, jsonlPath, purpose string) (*FileUploadResponse,
 		return nil, fmt.Errorf("無法關閉寫入器: %v", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, Url_UploadFiles, body)
+	req, err := self.newRequest(ctx, http.MethodPost, Path_UploadFiles, body)
 	if err != nil {
 		return nil, fmt.Errorf("無法建立請求: %v", err)
 	}
-
-	req.Header.Set("Authorization", "Bearer "+apiKey)
 	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	// 上傳會建立新檔案, 重送可能造成重複上傳
+	respBody, err := self.do(req, false)
 	if err != nil {
-		return nil, fmt.Errorf("請求失敗: %v", err)
-	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("無法讀取回應: %v", err)
+		return nil, err
 	}
 
 	var uploadResponse FileUploadResponse
@@ -391,196 +319,214 @@ func UploadFileRequest(apiKey, jsonlPath, purpose string) (*FileUploadResponse,
 }
 
 // 檔案列表查詢
-func ListFileRequest(apiKey string) (*ListFileResponse, error) {
-	req, err := http.NewRequest(http.MethodGet, Url_ListFiles, nil)
-	if err != nil {
+func (self *Client) ListFile() (*ListFileResponse, error) {
+	return self.ListFileContext(context.Background())
+}
+
+// 檔案列表查詢, 以 ctx 控制取消與逾時
+func (self *Client) ListFileContext(ctx context.Context) (*ListFileResponse, error) {
+	res := ListFileResponse{}
+	if err := self.doJSON(ctx, http.MethodGet, Path_ListFiles, nil, &res, true); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return &res, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// 檔案資訊查詢
+func (self *Client) RetrieveFile(fileId string) (*RetrieveFileResponse, error) {
+	return self.RetrieveFileContext(context.Background(), fileId)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+// 檔案資訊查詢, 以 ctx 控制取消與逾時
+func (self *Client) RetrieveFileContext(ctx context.Context, fileId string) (*RetrieveFileResponse, error) {
+	path := strings.ReplaceAll(Path_RetrieveFile, "{file_id}", fileId)
+
+	res := RetrieveFileResponse{}
+	if err := self.doJSON(ctx, http.MethodGet, path, nil, &res, true); err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
-
-		return nil, errors.New(errRes.Error.Message)
-
-	} else {
-		res := ListFileResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
-		}
+	return &res, nil
+}
 
-		return &res, nil
-	}
+// 刪除檔案
+func (self *Client) DeleteFile(fileId string) (*DeleteFileResponse, error) {
+	return self.DeleteFileContext(context.Background(), fileId)
 }
 
-// 檔案資訊查詢
-func RetrieveFileRequest(apiKey, fileId string) (*RetrieveFileResponse, error) {
+// 刪除檔案, 以 ctx 控制取消與逾時
+func (self *Client) DeleteFileContext(ctx context.Context, fileId string) (*DeleteFileResponse, error) {
+	path := strings.ReplaceAll(Path_DeleteFile, "{file_id}", fileId)
 
-	url := strings.ReplaceAll(Url_RetrieveFile, "{file_id}", fileId)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
+	res := DeleteFileResponse{}
+	if err := self.doJSON(ctx, http.MethodDelete, path, nil, &res, true); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return &res, nil
+}
+
+// 檢索檔案內文
+func (self *Client) RetrieveFileContent(fileId string, batchType string) (*RetrieveFileContentResponse, error) {
+	return self.RetrieveFileContentContext(context.Background(), fileId, batchType)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
+// 檢索檔案內文, 以 ctx 控制取消與逾時
+func (self *Client) RetrieveFileContentContext(ctx context.Context, fileId string, batchType string) (*RetrieveFileContentResponse, error) {
+	path := strings.ReplaceAll(Path_RetrieveFileContent, "{file_id}", fileId)
+	req, err := self.newRequest(ctx, http.MethodGet, path, nil)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := self.do(req, true)
 	if err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
+	res := RetrieveFileContentResponse{}
+	switch batchType {
+	case BatchType_Completions:
+		for _, data := range bytes.Split(body, []byte{'\n'}) {
+			if len(data) == 0 {
+				continue
+			}
+			rowData := BatchOutput{
+				Response: BatchOutputResData{
+					Body: &CompletionsResponse{},
+				}}
+			if err := json.Unmarshal(data, &rowData); err != nil {
+				return nil, fmt.Errorf("無法解析批次結果: %v", err)
+			}
+
+			res.Data = append(res.Data, rowData)
 		}
 
-		return nil, errors.New(errRes.Error.Message)
+	case BatchType_Embeddings:
+		for _, data := range bytes.Split(body, []byte{'\n'}) {
+			if len(data) == 0 {
+				continue
+			}
+			rowData := BatchOutput{
+				Response: BatchOutputResData{
+					Body: &EmbeddingsResponse{},
+				}}
+			if err := json.Unmarshal(data, &rowData); err != nil {
+				return nil, fmt.Errorf("無法解析批次結果: %v", err)
+			}
 
-	} else {
-		res := RetrieveFileResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
+			res.Data = append(res.Data, rowData)
 		}
-
-		return &res, nil
 	}
+
+	return &res, nil
 }
 
-// 刪除檔案
-func DeleteFileRequest(apiKey, fileId string) (*DeleteFileResponse, error) {
-	url := strings.ReplaceAll(Url_DeleteFile, "{file_id}", fileId)
-	req, err := http.NewRequest(http.MethodDelete, url, nil)
-	if err != nil {
+// ///// 模型查詢任務
+
+// 取得可使用的模型列表
+func (self *Client) ListModels() (*ListModelsResponse, error) {
+	return self.ListModelsContext(context.Background())
+}
+
+// 取得可使用的模型列表, 以 ctx 控制取消與逾時
+func (self *Client) ListModelsContext(ctx context.Context) (*ListModelsResponse, error) {
+	res := ListModelsResponse{}
+	if err := self.doJSON(ctx, http.MethodGet, Path_ListModels, nil, &res, true); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+	return &res, nil
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
+// 查詢指定模型
+func (self *Client) RetrieveModel(modelId string) (*RetrieveModelResponse, error) {
+	return self.RetrieveModelContext(context.Background(), modelId)
+}
+
+// 查詢指定模型, 以 ctx 控制取消與逾時
+func (self *Client) RetrieveModelContext(ctx context.Context, modelId string) (*RetrieveModelResponse, error) {
+	path := strings.ReplaceAll(Path_RetrieveModel, "{model}", url.PathEscape(modelId))
+
+	res := RetrieveModelResponse{}
+	if err := self.doJSON(ctx, http.MethodGet, path, nil, &res, true); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
+	return &res, nil
+}
+
+// 刪除微調模型, 需為模型擁有者
+func (self *Client) DeleteModel(modelId string) (*DeleteModelResponse, error) {
+	return self.DeleteModelContext(context.Background(), modelId)
+}
+
+// 刪除微調模型, 以 ctx 控制取消與逾時
+func (self *Client) DeleteModelContext(ctx context.Context, modelId string) (*DeleteModelResponse, error) {
+	path := strings.ReplaceAll(Path_DeleteModel, "{model}", url.PathEscape(modelId))
+
+	res := DeleteModelResponse{}
+	if err := self.doJSON(ctx, http.MethodDelete, path, nil, &res, true); err != nil {
 		return nil, err
 	}
 
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
+	return &res, nil
+}
 
-		return nil, errors.New(errRes.Error.Message)
+// ///// 以 apiKey 直接呼叫的函式, 使用預設設定的 Client
 
-	} else {
-		res := DeleteFileResponse{}
-		if err := json.Unmarshal(body, &res); err != nil {
-			return nil, err
-		}
+// 模型任務
+func CompletionsRequest(apiKey string, reqBody ChatCompletionRequest) (*CompletionsResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).Completions(reqBody)
+}
 
-		return &res, nil
-	}
+// 模型任務 以串流方式回應
+func CompletionsStreamingRequest(apiKey string, reqBody ChatCompletionRequest, output chan<- ChatCompletionChunk) error {
+	return NewClient(WithAPIKey(apiKey)).CompletionsStreaming(reqBody, output)
 }
 
-// 檢索檔案內文
-func RetrieveFileContentRequest(apiKey, fileId string, batchType string) (*RetrieveFileContentResponse, error) {
-	url := strings.ReplaceAll(Url_RetrueveFileContent, "{file_id}", fileId)
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
+// 建立新批次處理
+func CreateBatchRequest(apiKey, inputFileId string) (*CreateBatchResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).CreateBatch(inputFileId)
+}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+apiKey)
+// 取得已存在批次任務
+func ListBatchRequest(apiKey, after string, limit int) (*ListBatchResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).ListBatch(after, limit)
+}
 
-	client := &http.Client{}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// 查詢指定批次任務
+func RetrieveBatchRequest(apiKey, batchId string) (*RetrieveBatchResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).RetrieveBatch(batchId)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
+// 取消指定批次任務
+func CancelBatchRequest(apiKey, batchId string) (*CancelBatchResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).CancelBatch(batchId)
+}
 
-	if resp.StatusCode != 200 {
-		errRes := ErrorResponse{}
-		if err := json.Unmarshal(body, &errRes); err != nil {
-			return nil, err
-		}
+// 上傳檔案
+func UploadFileRequest(apiKey, jsonlPath, purpose string) (*FileUploadResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).UploadFile(jsonlPath, purpose)
+}
 
-		return nil, errors.New(errRes.Error.Message)
-
-	} else {
-
-		res := RetrieveFileContentResponse{}
-		switch batchType {
-		case BatchType_Completions:
-			for _, data := range bytes.Split(body, []byte{'\n'}) {
-				if len(data) == 0 {
-					continue
-				}
-				rowData := BatchOutput{
-					Response: BatchOutputResData{
-						Body: &CompletionsResponse{},
-					}}
-				if err := json.Unmarshal(data, &rowData); err != nil {
-					panic(err)
-				}
-
-				res.Data = append(res.Data, rowData)
-			}
+// 檔案列表查詢
+func ListFileRequest(apiKey string) (*ListFileResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).ListFile()
+}
 
-		case BatchType_Embeddings:
-			for _, data := range bytes.Split(body, []byte{'\n'}) {
-				if len(data) == 0 {
-					continue
-				}
-				rowData := BatchOutput{
-					Response: BatchOutputResData{
-						// Body: &EmbeddingsResponse{},
-					}}
-				if err := json.Unmarshal(data, &rowData); err != nil {
-					panic(err)
-				}
-
-				res.Data = append(res.Data, rowData)
-			}
-		}
+// 檔案資訊查詢
+func RetrieveFileRequest(apiKey, fileId string) (*RetrieveFileResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).RetrieveFile(fileId)
+}
 
-		return &res, nil
-	}
+// 刪除檔案
+func DeleteFileRequest(apiKey, fileId string) (*DeleteFileResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).DeleteFile(fileId)
+}
+
+// 檢索檔案內文
+func RetrieveFileContentRequest(apiKey, fileId string, batchType string) (*RetrieveFileContentResponse, error) {
+	return NewClient(WithAPIKey(apiKey)).RetrieveFileContent(fileId, batchType)
 }
@@ -0,0 +1,375 @@
+package gptapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// 對話紀錄處理策略, 在送出請求前縮減訊息避免超過模型的 context 長度
+//
+// 實作需保留系統提示, 且不可拆開工具呼叫與其對應的工具結果
+type IHistoryStrategy interface {
+	Apply(ctx context.Context, messages []IMessage) ([]IMessage, error)
+}
+
+// 計算單一訊息的 token 數量
+type TokenCounter func(message IMessage) int
+
+// 對請求的訊息套用紀錄處理策略, 送出前呼叫, 策略也可直接以 Apply 套用在 []IMessage 上
+//
+//	err := req.ApplyHistory(ctx, gptapi.NewTokenBudgetStrategy(8000, gptapi.NewMessageTokenCounter(req.Model)))
+func (self *ChatCompletionRequest) ApplyHistory(ctx context.Context, strategy IHistoryStrategy) error {
+	if strategy == nil {
+		return nil
+	}
+	messages, err := strategy.Apply(ctx, self.Messages)
+	if err != nil {
+		return err
+	}
+	self.Messages = messages
+	return nil
+}
+
+// 依序套用多個策略
+type HistoryStrategies []IHistoryStrategy
+
+func (self HistoryStrategies) Apply(ctx context.Context, messages []IMessage) ([]IMessage, error) {
+	var err error
+	for _, strategy := range self {
+		if messages, err = strategy.Apply(ctx, messages); err != nil {
+			return nil, err
+		}
+	}
+	return messages, nil
+}
+
+// 只保留最後 N 輪對話, 一輪從使用者訊息開始到下一則使用者訊息之前
+type LastTurnsStrategy struct {
+	Turns int // 保留的對話輪數
+}
+
+func NewLastTurnsStrategy(turns int) *LastTurnsStrategy {
+	return &LastTurnsStrategy{Turns: turns}
+}
+
+func (self *LastTurnsStrategy) Apply(ctx context.Context, messages []IMessage) ([]IMessage, error) {
+	if self.Turns <= 0 {
+		return nil, fmt.Errorf("[LastTurnsStrategy] Error turns must be positive: %d", self.Turns)
+	}
+
+	system, turns := splitTurns(messages)
+	if len(turns) > self.Turns {
+		turns = turns[len(turns)-self.Turns:]
+	}
+	return joinTurns(system, turns), nil
+}
+
+// 由最舊的訊息開始移除直到 token 數量不超過上限
+//
+// 系統提示一律保留, 工具呼叫與其工具結果一起移除, 最後一組訊息一律保留
+type TokenBudgetStrategy struct {
+	Budget  int          // token 數量上限
+	Counter TokenCounter // token 計算方式, 可用 NewMessageTokenCounter 建立, nil 時以訊息長度估算
+}
+
+func NewTokenBudgetStrategy(budget int, counter TokenCounter) *TokenBudgetStrategy {
+	return &TokenBudgetStrategy{
+		Budget:  budget,
+		Counter: counter,
+	}
+}
+
+func (self *TokenBudgetStrategy) Apply(ctx context.Context, messages []IMessage) ([]IMessage, error) {
+	if self.Budget <= 0 {
+		return nil, fmt.Errorf("[TokenBudgetStrategy] Error budget must be positive: %d", self.Budget)
+	}
+	counter := self.Counter
+	if counter == nil {
+		counter = estimateMessageTokens
+	}
+
+	system, turns := splitTurns(messages)
+	var groups [][]IMessage
+	for _, turn := range turns {
+		groups = append(groups, turn...)
+	}
+
+	total := 0
+	for _, message := range system {
+		total += counter(message)
+	}
+	counts := make([]int, len(groups))
+	for i, group := range groups {
+		for _, message := range group {
+			counts[i] += counter(message)
+		}
+		total += counts[i]
+	}
+
+	start := 0
+	for start < len(groups)-1 && total > self.Budget {
+		total -= counts[start]
+		start++
+	}
+
+	result := append([]IMessage(nil), system...)
+	for _, group := range groups[start:] {
+		result = append(result, group...)
+	}
+	return result, nil
+}
+
+// 將較舊的對話交由模型摘要, 以摘要取代原本的訊息
+//
+// 摘要併入最後一則系統提示 (或開發者指示), 沒有系統提示時新增一則
+// 摘要結果會暫存, 較舊的訊息沒有變動時不會重複呼叫模型
+type SummarizeStrategy struct {
+	Client *Client // 呼叫摘要用的客戶端
+	Model  string  // 摘要使用的模型, 空字串時使用客戶端預設模型
+	Turns  int     // 保留不摘要的最後對話輪數
+	Prompt string  // 摘要提示, 空字串時使用預設提示
+
+	mu      sync.Mutex
+	key     [sha256.Size]byte // 已摘要訊息的雜湊值
+	summary string            // 已摘要訊息的摘要結果
+}
+
+// 預設摘要提示
+const defaultSummarizePrompt = "Summarize the following conversation. Keep facts, decisions, user preferences and open questions needed to continue the conversation. Reply with the summary only."
+
+func NewSummarizeStrategy(client *Client, model string, turns int) *SummarizeStrategy {
+	return &SummarizeStrategy{
+		Client: client,
+		Model:  model,
+		Turns:  turns,
+	}
+}
+
+func (self *SummarizeStrategy) Apply(ctx context.Context, messages []IMessage) ([]IMessage, error) {
+	if self.Turns <= 0 {
+		return nil, fmt.Errorf("[SummarizeStrategy] Error turns must be positive: %d", self.Turns)
+	}
+	if self.Client == nil {
+		return nil, errors.New("[SummarizeStrategy] Error client is nil")
+	}
+
+	system, turns := splitTurns(messages)
+	if len(turns) <= self.Turns {
+		return messages, nil
+	}
+
+	older := joinTurns(nil, turns[:len(turns)-self.Turns])
+	summary, err := self.summarize(ctx, older)
+	if err != nil {
+		return nil, err
+	}
+
+	system = mergeSystemText(system, "Summary of the earlier conversation:\n"+summary)
+	return joinTurns(system, turns[len(turns)-self.Turns:]), nil
+}
+
+// 將文字併入最後一則系統提示或開發者指示, 不修改原訊息, 無法併入時新增一則系統提示
+func mergeSystemText(system []IMessage, text string) []IMessage {
+	system = append([]IMessage(nil), system...)
+	for i := len(system) - 1; i >= 0; i-- {
+		if merged, ok := appendMessageText(system[i], text); ok {
+			system[i] = merged
+			return system
+		}
+	}
+	return append(system, NewSystemTextMessage(text))
+}
+
+// 複製系統提示或開發者指示並在內文後附加文字
+func appendMessageText(message IMessage, text string) (IMessage, bool) {
+	switch m := message.(type) {
+	case *SystemMessage:
+		if m != nil {
+			return appendMessageText(*m, text)
+		}
+	case SystemMessage:
+		content, ok := appendContentText(m.Content, text)
+		if !ok {
+			return nil, false
+		}
+		m.Content = content
+		return &m, true
+	case *DeveloperMessage:
+		if m != nil {
+			return appendMessageText(*m, text)
+		}
+	case DeveloperMessage:
+		content, ok := appendContentText(m.Content, text)
+		if !ok {
+			return nil, false
+		}
+		m.Content = content
+		return &m, true
+	}
+	return nil, false
+}
+
+// 在內文後附加文字, 多段內文以新的文字段落附加
+func appendContentText(content IContent, text string) (IContent, bool) {
+	switch c := content.(type) {
+	case string:
+		if c == "" {
+			return text, true
+		}
+		return c + "\n\n" + text, true
+	case []ContentPart:
+		return append(append([]ContentPart(nil), c...), ContentPart{Type: MessageContentType_Text, Text: text}), true
+	}
+	return nil, false
+}
+
+// 取得訊息摘要, 訊息與上次相同時使用暫存結果
+func (self *SummarizeStrategy) summarize(ctx context.Context, messages []IMessage) (string, error) {
+	js, err := json.Marshal(messages)
+	if err != nil {
+		return "", fmt.Errorf("[SummarizeStrategy] Error marshal messages: %v", err)
+	}
+	key := sha256.Sum256(js)
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	if self.summary != "" && self.key == key {
+		return self.summary, nil
+	}
+
+	prompt := self.Prompt
+	if prompt == "" {
+		prompt = defaultSummarizePrompt
+	}
+	transcript := make([]string, 0, len(messages))
+	for _, message := range messages {
+		transcript = append(transcript, messageRole(message)+": "+messageText(message))
+	}
+
+	req := NewChatCompletionRequest(self.Model).
+		WithSystem(prompt).
+		WithUser(strings.Join(transcript, "\n"))
+	response, err := self.Client.CompletionsContext(ctx, *req)
+	if err != nil {
+		return "", err
+	}
+	if len(response.Choices) == 0 || response.Choices[0].Message.Content == "" {
+		return "", errors.New("[SummarizeStrategy] Error empty summary")
+	}
+
+	self.key = key
+	self.summary = response.Choices[0].Message.Content
+	return self.summary, nil
+}
+
+// 將訊息分為系統提示 (含開發者指示) 與對話輪次
+//
+// 每輪對話由多組訊息組成, 工具呼叫與其後的工具結果為同一組, 其他訊息各自一組
+func splitTurns(messages []IMessage) (system []IMessage, turns [][][]IMessage) {
+	for _, message := range messages {
+		switch messageRole(message) {
+		case MessageContentRole_System, MessageContentRole_Developer:
+			system = append(system, message)
+		case MessageContentRole_Tool:
+			// 工具結果附加到前一組工具呼叫
+			if len(turns) > 0 {
+				turn := turns[len(turns)-1]
+				turn[len(turn)-1] = append(turn[len(turn)-1], message)
+				continue
+			}
+			turns = append(turns, [][]IMessage{{message}})
+		case MessageContentRole_User:
+			turns = append(turns, [][]IMessage{{message}})
+		default:
+			if len(turns) == 0 {
+				turns = append(turns, nil)
+			}
+			turns[len(turns)-1] = append(turns[len(turns)-1], []IMessage{message})
+		}
+	}
+	return system, turns
+}
+
+// 將系統提示與對話輪次合併為訊息
+func joinTurns(system []IMessage, turns [][][]IMessage) []IMessage {
+	messages := append([]IMessage(nil), system...)
+	for _, turn := range turns {
+		for _, group := range turn {
+			messages = append(messages, group...)
+		}
+	}
+	return messages
+}
+
+// 取得訊息的 role
+func messageRole(message IMessage) string {
+	switch m := message.(type) {
+	case *SystemMessage:
+		return m.Role
+	case *DeveloperMessage:
+		return m.Role
+	case *UserMessage:
+		return m.Role
+	case *AssistantMessage:
+		return m.Role
+	case *ToolMessage:
+		return m.Role
+	case SystemMessage:
+		return m.Role
+	case DeveloperMessage:
+		return m.Role
+	case UserMessage:
+		return m.Role
+	case AssistantMessage:
+		return m.Role
+	case ToolMessage:
+		return m.Role
+	}
+
+	header := struct {
+		Role string `json:"role"`
+	}{}
+	js, _ := json.Marshal(message)
+	_ = json.Unmarshal(js, &header)
+	return header.Role
+}
+
+// 取得訊息中的文字內容, 用於摘要
+func messageText(message IMessage) string {
+	js, _ := json.Marshal(message)
+	data := struct {
+		Content   json.RawMessage `json:"content"`
+		ToolCalls []ToolCalls     `json:"tool_calls"`
+	}{}
+	_ = json.Unmarshal(js, &data)
+
+	var texts []string
+	content, _ := decodeContent(data.Content)
+	switch c := content.(type) {
+	case string:
+		texts = append(texts, c)
+	case []ContentPart:
+		for _, part := range c {
+			if part.Type == MessageContentType_Text {
+				texts = append(texts, part.Text)
+			} else {
+				texts = append(texts, "["+part.Type+"]")
+			}
+		}
+	}
+	for _, call := range data.ToolCalls {
+		texts = append(texts, fmt.Sprintf("[call %s(%s)]", call.Function.Name, call.Function.Arguments))
+	}
+	return strings.Join(texts, " ")
+}
+
+// 以訊息 json 長度估算 token 數量, 約 4 個字元為 1 個 token
+func estimateMessageTokens(message IMessage) int {
+	js, _ := json.Marshal(message)
+	return (len(js)+3)/4 + 4
+}
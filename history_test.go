@@ -0,0 +1,17 @@
+package gptapi
+
+import (
+	"context"
+	"testing"
+)
+
+func TestSummarizeStrategyNilClient(t *testing.T) {
+	messages := []IMessage{
+		NewUserTextMessage("first"),
+		NewAssistantTextMessage("reply"),
+		NewUserTextMessage("second"),
+	}
+	if _, err := NewSummarizeStrategy(nil, "", 1).Apply(context.Background(), messages); err == nil {
+		t.Error("Apply error = nil, want error")
+	}
+}
@@ -0,0 +1,344 @@
+package gptapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+)
+
+// 圖片處理設定
+type ImageOptions struct {
+	// 依解析度模式縮小圖片以節省 token, 空字串表示不縮放
+	// ImageDetailMode_Low: 長邊縮小至 512
+	// ImageDetailMode_High: 先縮小至 2048x2048 內, 再將短邊縮小至 768
+	Detail string
+	// 重新編碼 jpeg 時的品質 1~100, 0 表示使用預設值 85
+	Quality int
+}
+
+// 支援的圖片格式
+var supImageTypes = []string{ImageType_JPEG, ImageType_PNG, ImageType_GIF, ImageType_WEBP}
+
+// 由檔案內容判斷圖片格式, 回傳 MIME type
+func DetectImageType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(supImageTypes, contentType) {
+		return "", fmt.Errorf("[DetectImageType] Error not support content type: %s", contentType)
+	}
+	return contentType, nil
+}
+
+// 讀取圖片檔案並轉為 base64 編碼的 data URL
+func EncodeImageFile(imagepath string, options ImageOptions) (string, error) {
+	file, err := os.Open(imagepath)
+	if err != nil {
+		return "", fmt.Errorf("[EncodeImageFile] Error open: %s ,err: %v", imagepath, err)
+	}
+	defer file.Close()
+
+	return EncodeImageReader(file, options)
+}
+
+// 讀取圖片並轉為 base64 編碼的 data URL, 超過 ImageSizeLimit 時回傳錯誤
+func EncodeImageReader(reader io.Reader, options ImageOptions) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, int64(ImageSizeLimit)+1))
+	if err != nil {
+		return "", fmt.Errorf("[EncodeImageReader] Error ReadAll: %v", err)
+	}
+	return EncodeImageBytes(data, options)
+}
+
+// 將圖片檔案內容轉為 base64 編碼的 data URL
+//
+// 依檔案內容判斷格式, 需要縮放時重新編碼, png 與 jpeg 維持原格式, 其他格式轉為 png
+// jpeg 縮放前先依 EXIF 方向轉正, 重新編碼後不再帶有 EXIF
+// webp 無法以標準函式庫解碼, 動態 gif 重新編碼會遺失第一格以外的畫面, 兩者皆不進行縮放
+func EncodeImageBytes(data []byte, options ImageOptions) (string, error) {
+	if len(data) > ImageSizeLimit {
+		return "", fmt.Errorf("[EncodeImageBytes] Error image size exceeds limit: %d", ImageSizeLimit)
+	}
+	contentType, err := DetectImageType(data)
+	if err != nil {
+		return "", err
+	}
+
+	if options.Detail != "" && contentType != ImageType_WEBP && !isAnimatedGIF(contentType, data) {
+		config, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return "", fmt.Errorf("[EncodeImageBytes] Error decode config: %v", err)
+		}
+
+		orientation := 1
+		if contentType == ImageType_JPEG {
+			orientation = jpegOrientation(data)
+		}
+		sourceWidth, sourceHeight := config.Width, config.Height
+		if orientation >= 5 {
+			sourceWidth, sourceHeight = sourceHeight, sourceWidth
+		}
+
+		width, height := imageTargetSize(sourceWidth, sourceHeight, options.Detail)
+		if width != sourceWidth || height != sourceHeight {
+			img, _, err := image.Decode(bytes.NewReader(data))
+			if err != nil {
+				return "", fmt.Errorf("[EncodeImageBytes] Error decode: %v", err)
+			}
+			return encodeImage(resizeImage(orientImage(img, orientation), width, height), contentType, options)
+		}
+	}
+
+	return imageDataURL(contentType, data), nil
+}
+
+// 將 image.Image 編碼為 png 並轉為 base64 編碼的 data URL
+func EncodeImage(img image.Image, options ImageOptions) (string, error) {
+	bounds := img.Bounds()
+	if options.Detail != "" {
+		width, height := imageTargetSize(bounds.Dx(), bounds.Dy(), options.Detail)
+		if width != bounds.Dx() || height != bounds.Dy() {
+			img = resizeImage(img, width, height)
+		}
+	}
+	return encodeImage(img, ImageType_PNG, options)
+}
+
+// 編碼圖片並轉為 data URL, 只支援 jpeg 與 png 輸出, 其他格式以 png 輸出
+func encodeImage(img image.Image, contentType string, options ImageOptions) (string, error) {
+	buf := &bytes.Buffer{}
+	switch contentType {
+	case ImageType_JPEG:
+		quality := options.Quality
+		if quality <= 0 || 100 < quality {
+			quality = 85
+		}
+		if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return "", fmt.Errorf("[EncodeImage] Error jpeg encode: %v", err)
+		}
+	default:
+		contentType = ImageType_PNG
+		if err := png.Encode(buf, img); err != nil {
+			return "", fmt.Errorf("[EncodeImage] Error png encode: %v", err)
+		}
+	}
+
+	if buf.Len() > ImageSizeLimit {
+		return "", fmt.Errorf("[EncodeImage] Error image size exceeds limit: %d", ImageSizeLimit)
+	}
+	return imageDataURL(contentType, buf.Bytes()), nil
+}
+
+func imageDataURL(contentType string, data []byte) string {
+	return fmt.Sprintf("data:%s;base64,%s", contentType, base64.StdEncoding.EncodeToString(data))
+}
+
+// 依解析度模式計算縮小後的尺寸, 不會放大圖片
+func imageTargetSize(width, height int, detail string) (int, int) {
+	if width <= 0 || height <= 0 {
+		return width, height
+	}
+
+	w, h := float64(width), float64(height)
+	scale := 1.0
+	switch detail {
+	case ImageDetailMode_Low:
+		scale = min(1, imageTileSize/max(w, h))
+	case ImageDetailMode_High:
+		scale = min(1, imageMaxSide/max(w, h))
+		if min(w, h)*scale > imageShortSide {
+			scale = imageShortSide / min(w, h)
+		}
+	}
+	if scale >= 1 {
+		return width, height
+	}
+	return max(1, int(w*scale+0.5)), max(1, int(h*scale+0.5))
+}
+
+// 以區域平均縮小圖片
+func resizeImage(src image.Image, width, height int) image.Image {
+	// 轉為 RGBA 以便直接讀取像素
+	bounds := src.Bounds()
+	rgba, ok := src.(*image.RGBA)
+	if !ok {
+		rgba = image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+		draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+		bounds = rgba.Bounds()
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	scaleX := float64(bounds.Dx()) / float64(width)
+	scaleY := float64(bounds.Dy()) / float64(height)
+
+	for y := 0; y < height; y++ {
+		y0 := int(float64(y) * scaleY)
+		y1 := max(y0+1, int(float64(y+1)*scaleY))
+		for x := 0; x < width; x++ {
+			x0 := int(float64(x) * scaleX)
+			x1 := max(x0+1, int(float64(x+1)*scaleX))
+
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1 && sy < bounds.Dy(); sy++ {
+				offset := rgba.PixOffset(bounds.Min.X+x0, bounds.Min.Y+sy)
+				for sx := x0; sx < x1 && sx < bounds.Dx(); sx++ {
+					r += uint64(rgba.Pix[offset])
+					g += uint64(rgba.Pix[offset+1])
+					b += uint64(rgba.Pix[offset+2])
+					a += uint64(rgba.Pix[offset+3])
+					offset += 4
+					n++
+				}
+			}
+			if n == 0 {
+				continue
+			}
+			dst.SetRGBA(x, y, color.RGBA{
+				R: uint8(r / n),
+				G: uint8(g / n),
+				B: uint8(b / n),
+				A: uint8(a / n),
+			})
+		}
+	}
+	return dst
+}
+
+// 是否為多格的動態 gif
+func isAnimatedGIF(contentType string, data []byte) bool {
+	if contentType != ImageType_GIF {
+		return false
+	}
+	animation, err := gif.DecodeAll(bytes.NewReader(data))
+	return err == nil && len(animation.Image) > 1
+}
+
+// 讀取 jpeg EXIF 的方向設定 1~8, 沒有設定或無法解析時回傳 1
+func jpegOrientation(data []byte) int {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return 1
+	}
+
+	pos := 2
+	for pos+4 <= len(data) {
+		if data[pos] != 0xFF {
+			return 1
+		}
+		marker := data[pos+1]
+		switch {
+		case marker == 0xFF:
+			// 填充位元組
+			pos++
+			continue
+		case marker == 0x01 || (0xD0 <= marker && marker <= 0xD7):
+			// 沒有長度的標記
+			pos += 2
+			continue
+		case marker == 0xDA || marker == 0xD9:
+			// 影像資料開始, EXIF 只會出現在這之前
+			return 1
+		}
+
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		if length < 2 || pos+2+length > len(data) {
+			return 1
+		}
+		segment := data[pos+4 : pos+2+length]
+		if marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return exifOrientation(segment[6:])
+		}
+		pos += 2 + length
+	}
+	return 1
+}
+
+// 由 EXIF 的 TIFF 結構讀取 IFD0 的方向設定 (tag 0x0112)
+func exifOrientation(tiff []byte) int {
+	if len(tiff) < 8 {
+		return 1
+	}
+
+	var order binary.ByteOrder
+	switch string(tiff[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return 1
+	}
+
+	offset := int(order.Uint32(tiff[4:]))
+	if offset < 8 || offset+2 > len(tiff) {
+		return 1
+	}
+	count := int(order.Uint16(tiff[offset:]))
+	for i := 0; i < count; i++ {
+		entry := offset + 2 + i*12
+		if entry+12 > len(tiff) {
+			return 1
+		}
+		if order.Uint16(tiff[entry:]) != 0x0112 {
+			continue
+		}
+		if value := int(order.Uint16(tiff[entry+8:])); 1 <= value && value <= 8 {
+			return value
+		}
+		return 1
+	}
+	return 1
+}
+
+// 依 EXIF 方向設定轉正圖片
+//
+// 2: 水平翻轉, 3: 旋轉 180 度, 4: 垂直翻轉, 5: 沿左上對角線翻轉,
+// 6: 順時針旋轉 90 度, 7: 沿右上對角線翻轉, 8: 逆時針旋轉 90 度
+func orientImage(src image.Image, orientation int) image.Image {
+	if orientation < 2 || 8 < orientation {
+		return src
+	}
+
+	bounds := src.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), src, bounds.Min, draw.Src)
+
+	w, h := bounds.Dx(), bounds.Dy()
+	dstWidth, dstHeight := w, h
+	if orientation >= 5 {
+		dstWidth, dstHeight = h, w
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, dstWidth, dstHeight))
+
+	for y := 0; y < dstHeight; y++ {
+		for x := 0; x < dstWidth; x++ {
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], rgba.Pix[rgba.PixOffset(sx, sy):rgba.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
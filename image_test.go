@@ -0,0 +1,207 @@
+package gptapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/color/palette"
+	"image/gif"
+	"image/jpeg"
+	"strings"
+	"testing"
+)
+
+var (
+	quadrantRed   = color.RGBA{255, 0, 0, 255}
+	quadrantGreen = color.RGBA{0, 255, 0, 255}
+	quadrantBlue  = color.RGBA{0, 0, 255, 255}
+	quadrantWhite = color.RGBA{255, 255, 255, 255}
+)
+
+// 建立四個象限分別為 紅(左上) 綠(右上) 藍(左下) 白(右下) 的圖片
+func quadrantImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			switch {
+			case x < width/2 && y < height/2:
+				img.Set(x, y, quadrantRed)
+			case y < height/2:
+				img.Set(x, y, quadrantGreen)
+			case x < width/2:
+				img.Set(x, y, quadrantBlue)
+			default:
+				img.Set(x, y, quadrantWhite)
+			}
+		}
+	}
+	return img
+}
+
+// 在 jpeg 的 SOI 後插入只含方向設定的 EXIF 區段
+func jpegWithOrientation(t *testing.T, img image.Image, orientation int, order binary.ByteOrder) []byte {
+	buf := &bytes.Buffer{}
+	if err := jpeg.Encode(buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatalf("jpeg.Encode: %v", err)
+	}
+
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8) // IFD0 位置
+	order.PutUint16(tiff[8:], 1) // 欄位數量
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// 解碼 data URL 中的圖片
+func decodeDataURL(t *testing.T, url string) (image.Image, string) {
+	_, encoded, ok := strings.Cut(url, ";base64,")
+	if !ok {
+		t.Fatalf("invalid data url: %.40s", url)
+	}
+	data, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.Fatalf("base64: %v", err)
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("image.Decode: %v", err)
+	}
+	return img, format
+}
+
+// 比較顏色, 容許 jpeg 壓縮誤差
+func closeColor(a, b color.Color) bool {
+	ar, ag, ab, _ := a.RGBA()
+	br, bg, bb, _ := b.RGBA()
+	diff := func(x, y uint32) uint32 {
+		if x > y {
+			return x - y
+		}
+		return y - x
+	}
+	const tolerance = 0x2000
+	return diff(ar, br) < tolerance && diff(ag, bg) < tolerance && diff(ab, bb) < tolerance
+}
+
+func TestEncodeImageBytesOrientation(t *testing.T) {
+	// 顯示時四個象限的顏色 左上, 右上, 左下, 右下
+	tests := []struct {
+		orientation int
+		quadrants   [4]color.RGBA
+	}{
+		{1, [4]color.RGBA{quadrantRed, quadrantGreen, quadrantBlue, quadrantWhite}},
+		{2, [4]color.RGBA{quadrantGreen, quadrantRed, quadrantWhite, quadrantBlue}},
+		{3, [4]color.RGBA{quadrantWhite, quadrantBlue, quadrantGreen, quadrantRed}},
+		{4, [4]color.RGBA{quadrantBlue, quadrantWhite, quadrantRed, quadrantGreen}},
+		{5, [4]color.RGBA{quadrantRed, quadrantBlue, quadrantGreen, quadrantWhite}},
+		{6, [4]color.RGBA{quadrantBlue, quadrantRed, quadrantWhite, quadrantGreen}},
+		{7, [4]color.RGBA{quadrantWhite, quadrantGreen, quadrantBlue, quadrantRed}},
+		{8, [4]color.RGBA{quadrantGreen, quadrantWhite, quadrantRed, quadrantBlue}},
+	}
+
+	source := quadrantImage(1200, 600)
+	for _, tt := range tests {
+		for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+			data := jpegWithOrientation(t, source, tt.orientation, order)
+			if got := jpegOrientation(data); got != tt.orientation {
+				t.Errorf("orientation %d %s: jpegOrientation = %d", tt.orientation, order, got)
+			}
+
+			url, err := EncodeImageBytes(data, ImageOptions{Detail: ImageDetailMode_Low})
+			if err != nil {
+				t.Fatalf("orientation %d: EncodeImageBytes: %v", tt.orientation, err)
+			}
+			img, format := decodeDataURL(t, url)
+			if format != "jpeg" {
+				t.Errorf("orientation %d: format = %s, want jpeg", tt.orientation, format)
+			}
+
+			wantWidth, wantHeight := 512, 256
+			if tt.orientation >= 5 {
+				wantWidth, wantHeight = 256, 512
+			}
+			bounds := img.Bounds()
+			if bounds.Dx() != wantWidth || bounds.Dy() != wantHeight {
+				t.Errorf("orientation %d: size = %dx%d, want %dx%d", tt.orientation, bounds.Dx(), bounds.Dy(), wantWidth, wantHeight)
+				continue
+			}
+
+			points := [4]image.Point{
+				{wantWidth / 4, wantHeight / 4},
+				{wantWidth * 3 / 4, wantHeight / 4},
+				{wantWidth / 4, wantHeight * 3 / 4},
+				{wantWidth * 3 / 4, wantHeight * 3 / 4},
+			}
+			for i, point := range points {
+				if got := img.At(point.X, point.Y); !closeColor(got, tt.quadrants[i]) {
+					t.Errorf("orientation %d %s: quadrant %d = %v, want %v", tt.orientation, order, i, got, tt.quadrants[i])
+				}
+			}
+		}
+	}
+}
+
+func TestEncodeImageBytesNoResizeKeepsData(t *testing.T) {
+	data := jpegWithOrientation(t, quadrantImage(100, 50), 6, binary.BigEndian)
+	url, err := EncodeImageBytes(data, ImageOptions{Detail: ImageDetailMode_Low})
+	if err != nil {
+		t.Fatalf("EncodeImageBytes: %v", err)
+	}
+	if want := imageDataURL(ImageType_JPEG, data); url != want {
+		t.Error("image within size limit was re-encoded")
+	}
+}
+
+func TestEncodeImageBytesGIF(t *testing.T) {
+	frame := func(c color.Color) *image.Paletted {
+		img := image.NewPaletted(image.Rect(0, 0, 1000, 1000), palette.Plan9)
+		for i := range img.Pix {
+			img.Pix[i] = uint8(img.Palette.Index(c))
+		}
+		return img
+	}
+	encode := func(frames ...*image.Paletted) []byte {
+		buf := &bytes.Buffer{}
+		if err := gif.EncodeAll(buf, &gif.GIF{Image: frames, Delay: make([]int, len(frames))}); err != nil {
+			t.Fatalf("gif.EncodeAll: %v", err)
+		}
+		return buf.Bytes()
+	}
+
+	animated := encode(frame(quadrantRed), frame(quadrantBlue))
+	url, err := EncodeImageBytes(animated, ImageOptions{Detail: ImageDetailMode_Low})
+	if err != nil {
+		t.Fatalf("EncodeImageBytes animated: %v", err)
+	}
+	if want := imageDataURL(ImageType_GIF, animated); url != want {
+		t.Error("animated gif was re-encoded")
+	}
+
+	still := encode(frame(quadrantRed))
+	url, err = EncodeImageBytes(still, ImageOptions{Detail: ImageDetailMode_Low})
+	if err != nil {
+		t.Fatalf("EncodeImageBytes still: %v", err)
+	}
+	img, format := decodeDataURL(t, url)
+	if format != "png" || img.Bounds().Dx() != 512 || img.Bounds().Dy() != 512 {
+		t.Errorf("still gif = %s %v, want png 512x512", format, img.Bounds())
+	}
+}
@@ -0,0 +1,122 @@
+package gptapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// 訊息列表, json 解碼時依 role 還原為對應的訊息結構
+//
+// 用於 ChatCompletionRequest, 批次 Record 與 Conversation 的 json 解碼
+type Messages []IMessage
+
+func (self *Messages) UnmarshalJSON(js []byte) error {
+	if bytes.Equal(bytes.TrimSpace(js), []byte("null")) {
+		*self = nil
+		return nil
+	}
+
+	var raws []json.RawMessage
+	if err := json.Unmarshal(js, &raws); err != nil {
+		return fmt.Errorf("[Messages] Error %v", err)
+	}
+
+	messages := make(Messages, 0, len(raws))
+	for i, raw := range raws {
+		message, err := DecodeMessage(raw)
+		if err != nil {
+			return fmt.Errorf("[Messages] Error messages[%d]: %v", i, err)
+		}
+		messages = append(messages, message)
+	}
+	*self = messages
+	return nil
+}
+
+// 依 role 將 json 訊息解碼為對應的訊息結構
+//
+// 回傳 *SystemMessage, *DeveloperMessage, *UserMessage, *AssistantMessage 或 *ToolMessage
+// 內文為字串時維持 string, 為陣列時解碼為 []ContentPart
+func DecodeMessage(js []byte) (IMessage, error) {
+	header := struct {
+		Role    string          `json:"role"`
+		Content json.RawMessage `json:"content"`
+	}{}
+	if err := json.Unmarshal(js, &header); err != nil {
+		return nil, fmt.Errorf("[DecodeMessage] Error %v", err)
+	}
+
+	var message IMessage
+	var content *IContent
+	switch header.Role {
+	case MessageContentRole_System:
+		m := &SystemMessage{}
+		message, content = m, &m.Content
+	case MessageContentRole_Developer:
+		m := &DeveloperMessage{}
+		message, content = m, &m.Content
+	case MessageContentRole_User:
+		m := &UserMessage{}
+		message, content = m, &m.Content
+	case MessageContentRole_Assistant:
+		message = &AssistantMessage{}
+	case MessageContentRole_Tool:
+		m := &ToolMessage{}
+		message, content = m, &m.Content
+	default:
+		return nil, fmt.Errorf("[DecodeMessage] Error unknown role: %q", header.Role)
+	}
+
+	if err := json.Unmarshal(js, message); err != nil {
+		return nil, fmt.Errorf("[DecodeMessage] Error %s message: %v", header.Role, err)
+	}
+	if content != nil {
+		decoded, err := decodeContent(header.Content)
+		if err != nil {
+			return nil, fmt.Errorf("[DecodeMessage] Error %s content: %v", header.Role, err)
+		}
+		*content = decoded
+	}
+	return message, nil
+}
+
+// 解碼訊息內文, 字串維持字串, 陣列解碼為 []ContentPart
+func decodeContent(js json.RawMessage) (IContent, error) {
+	js = bytes.TrimSpace(js)
+	if len(js) == 0 || bytes.Equal(js, []byte("null")) {
+		return nil, nil
+	}
+
+	if js[0] == '"' {
+		var text string
+		err := json.Unmarshal(js, &text)
+		return text, err
+	}
+
+	var parts []ContentPart
+	if err := json.Unmarshal(js, &parts); err != nil {
+		return nil, err
+	}
+	return parts, nil
+}
+
+func (self *ChatCompletionRequest) UnmarshalJSON(js []byte) error {
+	type request ChatCompletionRequest
+	data := struct {
+		*request
+		ToolChoice json.RawMessage `json:"tool_choice,omitempty"`
+	}{
+		request: (*request)(self),
+	}
+	if err := json.Unmarshal(js, &data); err != nil {
+		return err
+	}
+
+	toolChoice, err := ParseToolChoice(data.ToolChoice)
+	if err != nil {
+		return err
+	}
+	self.ToolChoice = toolChoice
+	return nil
+}
@@ -0,0 +1,172 @@
+package gptapi
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 模型能力與限制
+type ModelInfo struct {
+	ID                string // 模型名稱
+	ContextWindow     int    // 輸入與輸出合計的 token 上限
+	MaxOutputTokens   int    // 輸出 token 上限, 0 表示不產生文字輸出 EX: embeddings 模型
+	Vision            bool   // 是否支援圖片輸入
+	Tools             bool   // 是否支援工具呼叫
+	StructuredOutputs bool   // 是否支援 json_schema 結構化輸出
+	Streaming         bool   // 是否支援串流回應
+	Reasoning         bool   // 是否為推理模型, 支援 reasoning_effort
+	InstructionRole   string // 系統提示需改用的 role, 空字串表示直接使用 "system" EX: "developer", 不支援系統提示的模型為 "user"
+}
+
+// 模型價格, 由價格表 (SetModelPricing) 取得
+func (self ModelInfo) Pricing() (ModelPricing, bool) {
+	return GetModelPricing(self.ID)
+}
+
+// 模型目錄, 依名稱查詢模型能力與限制
+type ModelRegistry struct {
+	mu     sync.RWMutex
+	models map[string]ModelInfo
+}
+
+func NewModelRegistry(models ...ModelInfo) *ModelRegistry {
+	registry := &ModelRegistry{
+		models: make(map[string]ModelInfo, len(models)),
+	}
+	for _, info := range models {
+		registry.models[info.ID] = info
+	}
+	return registry
+}
+
+// 內建的模型目錄, 可用 Register 新增或覆寫
+//
+// 能力與前綴模型不同的模型需個別列出, 避免以前綴查詢時沿用錯誤的能力 EX: "o1-preview" 不可沿用 "o1"
+var Models = NewModelRegistry(
+	ModelInfo{ID: "gpt-4o", ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4o-mini", ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4o-audio-preview", ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Streaming: true},
+	ModelInfo{ID: "gpt-4o-mini-audio-preview", ContextWindow: 128000, MaxOutputTokens: 16384, Tools: true, Streaming: true},
+	ModelInfo{ID: "gpt-4o-search-preview", ContextWindow: 128000, MaxOutputTokens: 16384, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4o-mini-search-preview", ContextWindow: 128000, MaxOutputTokens: 16384, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4.1", ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4.1-mini", ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4.1-nano", ContextWindow: 1047576, MaxOutputTokens: 32768, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "o1", ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "o1-mini", ContextWindow: 128000, MaxOutputTokens: 65536, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_User},
+	ModelInfo{ID: "o1-preview", ContextWindow: 128000, MaxOutputTokens: 32768, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_User},
+	ModelInfo{ID: "o3", ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "o3-mini", ContextWindow: 200000, MaxOutputTokens: 100000, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "o4-mini", ContextWindow: 200000, MaxOutputTokens: 100000, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "gpt-5", ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "gpt-5-mini", ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "gpt-5-nano", ContextWindow: 400000, MaxOutputTokens: 128000, Vision: true, Tools: true, StructuredOutputs: true, Streaming: true, Reasoning: true, InstructionRole: MessageContentRole_Developer},
+	ModelInfo{ID: "gpt-5-chat-latest", ContextWindow: 128000, MaxOutputTokens: 16384, Vision: true, StructuredOutputs: true, Streaming: true},
+	ModelInfo{ID: "gpt-4-turbo", ContextWindow: 128000, MaxOutputTokens: 4096, Vision: true, Tools: true, Streaming: true},
+	ModelInfo{ID: "gpt-4-turbo-preview", ContextWindow: 128000, MaxOutputTokens: 4096, Tools: true, Streaming: true},
+	ModelInfo{ID: "gpt-4", ContextWindow: 8192, MaxOutputTokens: 8192, Tools: true, Streaming: true},
+	ModelInfo{ID: "gpt-4-32k", ContextWindow: 32768, MaxOutputTokens: 32768, Tools: true, Streaming: true},
+	ModelInfo{ID: "gpt-3.5-turbo", ContextWindow: 16385, MaxOutputTokens: 4096, Tools: true, Streaming: true},
+	ModelInfo{ID: "text-embedding-3-small", ContextWindow: 8191},
+	ModelInfo{ID: "text-embedding-3-large", ContextWindow: 8191},
+	ModelInfo{ID: "text-embedding-ada-002", ContextWindow: 8191},
+)
+
+// 新增或覆寫模型資訊
+func (self *ModelRegistry) Register(info ModelInfo) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.models[info.ID] = info
+}
+
+// 取得模型資訊
+//
+// 找不到完全相同的模型名稱時, 以最長的前綴相符模型為準 EX: "gpt-4o-2024-08-06" 使用 "gpt-4o"
+// 微調模型 "ft:gpt-4o-mini-2024-07-18:org::id" 使用基礎模型的資訊
+func (self *ModelRegistry) Get(model string) (ModelInfo, bool) {
+	if base, ok := strings.CutPrefix(model, "ft:"); ok {
+		model, _, _ = strings.Cut(base, ":")
+	}
+
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	if info, ok := self.models[model]; ok {
+		return info, true
+	}
+
+	best := ""
+	for name := range self.models {
+		if strings.HasPrefix(model, name+"-") && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return ModelInfo{}, false
+	}
+	return self.models[best], true
+}
+
+// 所有模型資訊, 依名稱排序
+func (self *ModelRegistry) List() []ModelInfo {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	models := make([]ModelInfo, 0, len(self.models))
+	for _, info := range self.models {
+		models = append(models, info)
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+	return models
+}
+
+// 依模型目錄檢查請求是否使用模型不支援的功能或超過模型限制
+//
+// 模型不在 Models 中時不檢查, 回傳的警告不影響請求送出
+// Client 設定 WithWarningHandler 時, Completions 送出前會自動檢查並回報
+func (self *ChatCompletionRequest) Warnings() []string {
+	info, ok := Models.Get(self.Model)
+	if !ok {
+		return nil
+	}
+
+	var warnings []string
+	images := 0
+	for _, message := range self.Messages {
+		count, _ := contentPartsStats(messageContentParts(message))
+		images += count
+	}
+	if images > 0 && !info.Vision {
+		warnings = append(warnings, fmt.Sprintf("model %s does not support image input, %d images in messages", self.Model, images))
+	}
+	if len(self.Tools) > 0 && !info.Tools {
+		warnings = append(warnings, fmt.Sprintf("model %s does not support tools", self.Model))
+	}
+	if self.ResponseFormat != nil && self.ResponseFormat.Type == ResponseFormatType_JSONSchema && !info.StructuredOutputs {
+		warnings = append(warnings, fmt.Sprintf("model %s does not support json_schema response format", self.Model))
+	}
+	if self.Stream && !info.Streaming {
+		warnings = append(warnings, fmt.Sprintf("model %s does not support streaming", self.Model))
+	}
+	if self.ReasoningEffort != "" && !info.Reasoning {
+		warnings = append(warnings, fmt.Sprintf("model %s does not support reasoning_effort", self.Model))
+	}
+
+	maxTokens := max(self.MaxTokens, self.MaxCompletionTokens)
+	if info.MaxOutputTokens > 0 && maxTokens > info.MaxOutputTokens {
+		warnings = append(warnings, fmt.Sprintf("max tokens %d exceeds model %s output limit %d", maxTokens, self.Model, info.MaxOutputTokens))
+	}
+	if info.ContextWindow > 0 {
+		if prompt, exact, err := EstimateTokens(self.Model, self.Messages, self.Tools); err == nil && prompt+maxTokens > info.ContextWindow {
+			kind := "prompt"
+			if !exact {
+				kind = "estimated prompt"
+			}
+			warnings = append(warnings, fmt.Sprintf("%s %d tokens plus max tokens %d exceeds model %s context window %d", kind, prompt, maxTokens, self.Model, info.ContextWindow))
+		}
+	}
+
+	return warnings
+}
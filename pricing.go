@@ -0,0 +1,129 @@
+package gptapi
+
+import (
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// 批次 API 折扣, 批次請求費用為一般請求的 50%
+const BatchDiscount float64 = 0.5
+
+// 模型價格, 單位為每百萬 token 美金
+type ModelPricing struct {
+	Input       float64 // 輸入 token 價格
+	CachedInput float64 // 命中提示快取的輸入 token 價格, 0 表示不支援快取, 以 Input 計算
+	Output      float64 // 輸出 token 價格, 含推理 token
+	AudioInput  float64 // 音訊輸入 token 價格, 0 表示以 Input 計算
+	AudioOutput float64 // 音訊輸出 token 價格, 0 表示以 Output 計算
+	PerRequest  float64 // 每次請求的固定費用, 單位為美金 EX: 搜尋模型每次請求的網路搜尋費用
+}
+
+var (
+	pricingMu sync.RWMutex
+
+	// 模型價格表, 價格可能隨官方調整, 可用 SetModelPricing 覆寫
+	//
+	// 模型目錄 Models 中的每個模型都需個別列出, 不以其他模型的價格代替
+	pricingTable = map[string]ModelPricing{
+		"gpt-4o":                     {Input: 2.50, CachedInput: 1.25, Output: 10.00},
+		"gpt-4o-mini":                {Input: 0.15, CachedInput: 0.075, Output: 0.60},
+		"gpt-4o-audio-preview":       {Input: 2.50, Output: 10.00, AudioInput: 40.00, AudioOutput: 80.00},
+		"gpt-4o-mini-audio-preview":  {Input: 0.15, Output: 0.60, AudioInput: 10.00, AudioOutput: 20.00},
+		"gpt-4o-search-preview":      {Input: 2.50, Output: 10.00, PerRequest: 0.035},
+		"gpt-4o-mini-search-preview": {Input: 0.15, Output: 0.60, PerRequest: 0.0275},
+		"gpt-4.1":                    {Input: 2.00, CachedInput: 0.50, Output: 8.00},
+		"gpt-4.1-mini":               {Input: 0.40, CachedInput: 0.10, Output: 1.60},
+		"gpt-4.1-nano":               {Input: 0.10, CachedInput: 0.025, Output: 0.40},
+		"gpt-5":                      {Input: 1.25, CachedInput: 0.125, Output: 10.00},
+		"gpt-5-mini":                 {Input: 0.25, CachedInput: 0.025, Output: 2.00},
+		"gpt-5-nano":                 {Input: 0.05, CachedInput: 0.005, Output: 0.40},
+		"gpt-5-chat-latest":          {Input: 1.25, CachedInput: 0.125, Output: 10.00},
+		"o1":                         {Input: 15.00, CachedInput: 7.50, Output: 60.00},
+		"o1-mini":                    {Input: 1.10, CachedInput: 0.55, Output: 4.40},
+		"o1-preview":                 {Input: 15.00, CachedInput: 7.50, Output: 60.00},
+		"o3":                         {Input: 2.00, CachedInput: 0.50, Output: 8.00},
+		"o3-mini":                    {Input: 1.10, CachedInput: 0.55, Output: 4.40},
+		"o4-mini":                    {Input: 1.10, CachedInput: 0.275, Output: 4.40},
+		"gpt-4-turbo":                {Input: 10.00, Output: 30.00},
+		"gpt-4-turbo-preview":        {Input: 10.00, Output: 30.00},
+		"gpt-4":                      {Input: 30.00, Output: 60.00},
+		"gpt-4-32k":                  {Input: 60.00, Output: 120.00},
+		"gpt-3.5-turbo":              {Input: 0.50, Output: 1.50},
+		"text-embedding-3-small":     {Input: 0.02},
+		"text-embedding-3-large":     {Input: 0.13},
+		"text-embedding-ada-002":     {Input: 0.10},
+	}
+)
+
+// 帶有日期的模型快照名稱 EX: "gpt-4o-2024-08-06"
+var snapshotPattern = regexp.MustCompile(`^(.+)-\d{4}-\d{2}-\d{2}$`)
+
+// 設定模型價格, 已存在時覆寫
+func SetModelPricing(model string, pricing ModelPricing) {
+	pricingMu.Lock()
+	defer pricingMu.Unlock()
+	pricingTable[model] = pricing
+}
+
+// 取得模型價格
+//
+// 找不到完全相同的模型名稱時, 日期快照使用基礎模型的價格 EX: "gpt-4o-2024-08-06" 使用 "gpt-4o" 的價格
+// 其他名稱不以前綴相符的模型代替, 避免 "gpt-4-32k" 誤用 "gpt-4" 的價格
+func GetModelPricing(model string) (ModelPricing, bool) {
+	pricingMu.RLock()
+	defer pricingMu.RUnlock()
+
+	if pricing, ok := pricingTable[model]; ok {
+		return pricing, true
+	}
+
+	if match := snapshotPattern.FindStringSubmatch(model); match != nil {
+		if pricing, ok := pricingTable[match[1]]; ok {
+			return pricing, true
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// 計算單次請求的費用, 單位為美金
+//
+// 音訊 token 以 AudioInput 與 AudioOutput 計算, PerRequest 每次呼叫計算一次
+func (self ModelPricing) Cost(usage Usage) float64 {
+	cached := usage.PromptTokensDetails.CachedTokens
+	audioInput := usage.PromptTokensDetails.AudioTokens
+	audioOutput := usage.CompletionTokensDetails.AudioTokens
+
+	cost := float64(usage.PromptTokens-cached-audioInput)*self.Input +
+		float64(cached)*priceOr(self.CachedInput, self.Input) +
+		float64(audioInput)*priceOr(self.AudioInput, self.Input) +
+		float64(usage.CompletionTokens-audioOutput)*self.Output +
+		float64(audioOutput)*priceOr(self.AudioOutput, self.Output)
+	return cost/1_000_000 + self.PerRequest
+}
+
+// 價格為 0 時使用替代價格
+func priceOr(price, fallback float64) float64 {
+	if price == 0 {
+		return fallback
+	}
+	return price
+}
+
+// 計算一般請求的費用, 單位為美金
+func Cost(usage Usage, model string) (float64, error) {
+	pricing, ok := GetModelPricing(model)
+	if !ok {
+		return 0, fmt.Errorf("[Cost] Error unknown model pricing: %s", model)
+	}
+	return pricing.Cost(usage), nil
+}
+
+// 計算批次請求的費用, 套用 BatchDiscount, 單位為美金
+func BatchCost(usage Usage, model string) (float64, error) {
+	pricing, ok := GetModelPricing(model)
+	if !ok {
+		return 0, fmt.Errorf("[BatchCost] Error unknown model pricing: %s", model)
+	}
+	return pricing.Cost(usage) * BatchDiscount, nil
+}
@@ -0,0 +1,69 @@
+package gptapi
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCatalogModelsHavePricing(t *testing.T) {
+	for _, info := range Models.List() {
+		if _, ok := info.Pricing(); !ok {
+			t.Errorf("model %s has no pricing", info.ID)
+		}
+	}
+}
+
+func TestGetModelPricing(t *testing.T) {
+	tests := []struct {
+		model string
+		want  ModelPricing
+		ok    bool
+	}{
+		{"gpt-4o", ModelPricing{Input: 2.50, CachedInput: 1.25, Output: 10.00}, true},
+		{"gpt-4o-2024-08-06", ModelPricing{Input: 2.50, CachedInput: 1.25, Output: 10.00}, true},
+		{"o3-mini-2025-01-31", ModelPricing{Input: 1.10, CachedInput: 0.55, Output: 4.40}, true},
+		{"gpt-4-32k", ModelPricing{Input: 60.00, Output: 120.00}, true},
+		{"o1-preview-2024-09-12", ModelPricing{Input: 15.00, CachedInput: 7.50, Output: 60.00}, true},
+		{"gpt-4o-realtime-preview", ModelPricing{}, false},
+		{"gpt-4-0613", ModelPricing{}, false},
+		{"unknown", ModelPricing{}, false},
+	}
+
+	for _, tt := range tests {
+		got, ok := GetModelPricing(tt.model)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("GetModelPricing(%s) = %+v, %v, want %+v, %v", tt.model, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestModelPricingCost(t *testing.T) {
+	tests := []struct {
+		name  string
+		model string
+		usage Usage
+		want  float64
+	}{
+		{"text", "gpt-4o", Usage{PromptTokens: 1_000_000, CompletionTokens: 1_000_000}, 12.50},
+		{"cached", "gpt-4o", Usage{PromptTokens: 1_000_000, PromptTokensDetails: PromptTokensDetails{CachedTokens: 400_000}}, 0.6*2.50 + 0.4*1.25},
+		{"audio", "gpt-4o-audio-preview", Usage{
+			PromptTokens:            1_000_000,
+			CompletionTokens:        1_000_000,
+			PromptTokensDetails:     PromptTokensDetails{AudioTokens: 500_000},
+			CompletionTokensDetails: CompletionTokensDetails{AudioTokens: 500_000},
+		}, 0.5*2.50 + 0.5*40.00 + 0.5*10.00 + 0.5*80.00},
+		{"per request", "gpt-4o-mini-search-preview", Usage{PromptTokens: 1000}, 1000*0.15/1_000_000 + 0.0275},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Cost(tt.usage, tt.model)
+			if err != nil {
+				t.Fatalf("Cost: %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
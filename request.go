@@ -0,0 +1,399 @@
+package gptapi
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// 工具函數與 schema 名稱格式限制
+var toolNamePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// 建立指定模型的 Completions 請求
+func NewChatCompletionRequest(model string) *ChatCompletionRequest {
+	return &ChatCompletionRequest{
+		Model: model,
+	}
+}
+
+// 設定模型
+func (self *ChatCompletionRequest) WithModel(model string) *ChatCompletionRequest {
+	self.Model = model
+	return self
+}
+
+// 設定最大 token 使用數量
+func (self *ChatCompletionRequest) WithMaxTokens(maxTokens int) *ChatCompletionRequest {
+	self.MaxTokens = maxTokens
+	return self
+}
+
+// 附加訊息
+func (self *ChatCompletionRequest) WithMessages(messages ...IMessage) *ChatCompletionRequest {
+	self.Messages = append(self.Messages, messages...)
+	return self
+}
+
+// 附加系統提示訊息
+func (self *ChatCompletionRequest) WithSystem(text string) *ChatCompletionRequest {
+	return self.WithMessages(NewSystemTextMessage(text))
+}
+
+// 附加開發者指示訊息, 推理模型使用
+func (self *ChatCompletionRequest) WithDeveloper(text string) *ChatCompletionRequest {
+	return self.WithMessages(NewDeveloperTextMessage(text))
+}
+
+// 附加使用者文字訊息
+func (self *ChatCompletionRequest) WithUser(text string) *ChatCompletionRequest {
+	return self.WithMessages(NewUserTextMessage(text))
+}
+
+// 附加包含圖片的使用者訊息
+//
+// @imageUrl 圖片"網址"或 ImageEncode 產生的"base64編碼圖片"
+func (self *ChatCompletionRequest) WithImage(text, imageUrl string) *ChatCompletionRequest {
+	return self.WithMessages(NewUserImageMessage(text, imageUrl))
+}
+
+// 附加 AI 回應訊息, 用於提供對話範例或歷史
+func (self *ChatCompletionRequest) WithAssistant(text string) *ChatCompletionRequest {
+	return self.WithMessages(NewAssistantTextMessage(text))
+}
+
+// 附加工具
+func (self *ChatCompletionRequest) WithTools(tools ...Tool) *ChatCompletionRequest {
+	self.Tools = append(self.Tools, tools...)
+	return self
+}
+
+// 設定工具選擇方式
+func (self *ChatCompletionRequest) WithToolChoice(toolChoice IToolChoice) *ChatCompletionRequest {
+	self.ToolChoice = toolChoice
+	return self
+}
+
+// 設定模型輸出格式
+func (self *ChatCompletionRequest) WithResponseFormat(format ResponseFormat) *ChatCompletionRequest {
+	self.ResponseFormat = &format
+	return self
+}
+
+// 設定取樣溫度
+func (self *ChatCompletionRequest) WithTemperature(temperature float64) *ChatCompletionRequest {
+	self.Temperature = &temperature
+	return self
+}
+
+// 設定核取樣機率
+func (self *ChatCompletionRequest) WithTopP(topP float64) *ChatCompletionRequest {
+	self.TopP = &topP
+	return self
+}
+
+// 設定回應數量
+func (self *ChatCompletionRequest) WithN(n int) *ChatCompletionRequest {
+	self.N = n
+	return self
+}
+
+// 設定停止序列
+func (self *ChatCompletionRequest) WithStop(stop ...string) *ChatCompletionRequest {
+	self.Stop = stop
+	return self
+}
+
+// 設定存在懲罰
+func (self *ChatCompletionRequest) WithPresencePenalty(penalty float64) *ChatCompletionRequest {
+	self.PresencePenalty = &penalty
+	return self
+}
+
+// 設定頻率懲罰
+func (self *ChatCompletionRequest) WithFrequencyPenalty(penalty float64) *ChatCompletionRequest {
+	self.FrequencyPenalty = &penalty
+	return self
+}
+
+// 設定 token 機率偏差, key 為 token id
+func (self *ChatCompletionRequest) WithLogitBias(logitBias map[string]int) *ChatCompletionRequest {
+	self.LogitBias = logitBias
+	return self
+}
+
+// 設定取樣種子
+func (self *ChatCompletionRequest) WithSeed(seed int) *ChatCompletionRequest {
+	self.Seed = &seed
+	return self
+}
+
+// 設定終端使用者識別
+func (self *ChatCompletionRequest) WithEndUserID(user string) *ChatCompletionRequest {
+	self.User = user
+	return self
+}
+
+// 開啟對數機率輸出
+//
+// @topLogprobs 每個位置額外回傳的候選 token 數量, 0 表示不回傳候選
+func (self *ChatCompletionRequest) WithLogprobs(topLogprobs int) *ChatCompletionRequest {
+	self.Logprobs = true
+	self.TopLogprobs = &topLogprobs
+	return self
+}
+
+// 設定是否允許同時呼叫多個工具
+func (self *ChatCompletionRequest) WithParallelToolCalls(parallel bool) *ChatCompletionRequest {
+	self.ParallelToolCalls = &parallel
+	return self
+}
+
+// 設定輸出 token 上限 (含推理 token)
+func (self *ChatCompletionRequest) WithMaxCompletionTokens(maxTokens int) *ChatCompletionRequest {
+	self.MaxCompletionTokens = maxTokens
+	return self
+}
+
+// 設定推理模型思考程度
+func (self *ChatCompletionRequest) WithReasoningEffort(effort string) *ChatCompletionRequest {
+	self.ReasoningEffort = effort
+	return self
+}
+
+// 設定服務層級
+func (self *ChatCompletionRequest) WithServiceTier(serviceTier string) *ChatCompletionRequest {
+	self.ServiceTier = serviceTier
+	return self
+}
+
+// 儲存本次輸出並附加自訂標籤
+func (self *ChatCompletionRequest) WithStore(metadata map[string]string) *ChatCompletionRequest {
+	store := true
+	self.Store = &store
+	self.Metadata = metadata
+	return self
+}
+
+// 設定串流回應選項
+func (self *ChatCompletionRequest) WithStreamOptions(includeUsage bool) *ChatCompletionRequest {
+	self.StreamOptions = &StreamOptions{IncludeUsage: includeUsage}
+	return self
+}
+
+// 送出前檢查請求內容, 回傳所有發現的問題
+func (self *ChatCompletionRequest) Validate() error {
+	var errs []error
+
+	if self.Model == "" {
+		errs = append(errs, errors.New("[Validate] Error model is empty"))
+	}
+
+	if len(self.Messages) == 0 {
+		errs = append(errs, errors.New("[Validate] Error messages is empty"))
+	}
+	images, size := 0, 0
+	for i, message := range self.Messages {
+		if message == nil {
+			errs = append(errs, fmt.Errorf("[Validate] Error messages[%d] is nil", i))
+		}
+		messageImages, messageSize := contentPartsStats(messageContentParts(message))
+		images += messageImages
+		size += messageSize
+	}
+	if images > ImageCountLimit {
+		errs = append(errs, fmt.Errorf("[Validate] Error too many images: %d, limit: %d", images, ImageCountLimit))
+	}
+	if size > PayloadSizeLimit {
+		errs = append(errs, fmt.Errorf("[Validate] Error content payload too large: %d bytes, limit: %d", size, PayloadSizeLimit))
+	}
+
+	if len(self.Tools) > ToolsLimit {
+		errs = append(errs, fmt.Errorf("[Validate] Error too many tools: %d, limit: %d", len(self.Tools), ToolsLimit))
+	}
+	toolNames := make(map[string]bool, len(self.Tools))
+	for i, tool := range self.Tools {
+		name := tool.ToolFunction.Name
+		if !toolNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("[Validate] Error tools[%d] invalid function name: %q", i, name))
+		} else if toolNames[name] {
+			errs = append(errs, fmt.Errorf("[Validate] Error tools[%d] duplicate function name: %q", i, name))
+		}
+		toolNames[name] = true
+
+		if tool.IsStrict() {
+			if err := tool.ToolFunction.Parameters.ValidateStrict(); err != nil {
+				errs = append(errs, fmt.Errorf("[Validate] Error tools[%d] %q strict parameters: %w", i, name, err))
+			}
+		}
+	}
+	if self.ToolChoice != nil && len(self.Tools) == 0 {
+		errs = append(errs, errors.New("[Validate] Error tool_choice is set without tools"))
+	}
+	switch choice := derefToolChoice(self.ToolChoice).(type) {
+	case ToolChoiceString:
+		if !slices.Contains([]string{ToolChoice_None, ToolChoice_Auto, ToolChoice_Required}, string(choice)) {
+			errs = append(errs, fmt.Errorf("[Validate] Error tool_choice invalid value: %q", string(choice)))
+		}
+	case ToolChoiceObject:
+		if choice.Type != ToolType_Function {
+			errs = append(errs, fmt.Errorf("[Validate] Error tool_choice invalid type: %q", choice.Type))
+		}
+		if len(self.Tools) > 0 && !toolNames[choice.ToolChoiceFunction.Name] {
+			errs = append(errs, fmt.Errorf("[Validate] Error tool_choice function not in tools: %q", choice.ToolChoiceFunction.Name))
+		}
+	}
+	if self.ParallelToolCalls != nil && len(self.Tools) == 0 {
+		errs = append(errs, errors.New("[Validate] Error parallel_tool_calls is set without tools"))
+	}
+
+	if self.MaxTokens < 0 || self.MaxCompletionTokens < 0 {
+		errs = append(errs, errors.New("[Validate] Error max tokens must not be negative"))
+	}
+	if self.Temperature != nil && (*self.Temperature < 0 || 2 < *self.Temperature) {
+		errs = append(errs, fmt.Errorf("[Validate] Error temperature out of range 0~2: %v", *self.Temperature))
+	}
+	if self.TopP != nil && (*self.TopP < 0 || 1 < *self.TopP) {
+		errs = append(errs, fmt.Errorf("[Validate] Error top_p out of range 0~1: %v", *self.TopP))
+	}
+	if self.PresencePenalty != nil && (*self.PresencePenalty < -2 || 2 < *self.PresencePenalty) {
+		errs = append(errs, fmt.Errorf("[Validate] Error presence_penalty out of range -2~2: %v", *self.PresencePenalty))
+	}
+	if self.FrequencyPenalty != nil && (*self.FrequencyPenalty < -2 || 2 < *self.FrequencyPenalty) {
+		errs = append(errs, fmt.Errorf("[Validate] Error frequency_penalty out of range -2~2: %v", *self.FrequencyPenalty))
+	}
+	if self.N < 0 {
+		errs = append(errs, fmt.Errorf("[Validate] Error n must not be negative: %d", self.N))
+	}
+	if len(self.Stop) > 4 {
+		errs = append(errs, fmt.Errorf("[Validate] Error too many stop sequences: %d, limit: 4", len(self.Stop)))
+	}
+	if self.TopLogprobs != nil {
+		if !self.Logprobs {
+			errs = append(errs, errors.New("[Validate] Error top_logprobs requires logprobs"))
+		} else if *self.TopLogprobs < 0 || 20 < *self.TopLogprobs {
+			errs = append(errs, fmt.Errorf("[Validate] Error top_logprobs out of range 0~20: %d", *self.TopLogprobs))
+		}
+	}
+	for token, bias := range self.LogitBias {
+		if bias < -100 || 100 < bias {
+			errs = append(errs, fmt.Errorf("[Validate] Error logit_bias[%s] out of range -100~100: %d", token, bias))
+		}
+	}
+	if self.ResponseFormat != nil && self.ResponseFormat.Type == ResponseFormatType_JSONSchema {
+		if self.ResponseFormat.JSONSchema == nil {
+			errs = append(errs, errors.New("[Validate] Error response_format json_schema is empty"))
+		} else if !toolNamePattern.MatchString(self.ResponseFormat.JSONSchema.Name) {
+			errs = append(errs, fmt.Errorf("[Validate] Error response_format invalid schema name: %q", self.ResponseFormat.JSONSchema.Name))
+		} else if self.ResponseFormat.JSONSchema.Schema == nil {
+			errs = append(errs, errors.New("[Validate] Error response_format schema is empty"))
+		} else if self.ResponseFormat.JSONSchema.Strict {
+			if err := self.ResponseFormat.JSONSchema.Schema.ValidateStrict(); err != nil {
+				errs = append(errs, fmt.Errorf("[Validate] Error response_format strict schema: %w", err))
+			}
+		}
+	}
+	if self.StreamOptions != nil && !self.Stream {
+		errs = append(errs, errors.New("[Validate] Error stream_options requires stream"))
+	}
+	if self.ReasoningEffort != "" && !slices.Contains([]string{ReasoningEffort_Minimal, ReasoningEffort_Low, ReasoningEffort_Medium, ReasoningEffort_High}, self.ReasoningEffort) {
+		errs = append(errs, fmt.Errorf("[Validate] Error reasoning_effort invalid value: %q", self.ReasoningEffort))
+	}
+
+	return errors.Join(errs...)
+}
+
+// 是否為推理模型, 推理模型使用 max_completion_tokens
+//
+// 依模型目錄 Models 判斷, 不在目錄中的模型視為非推理模型
+func IsReasoningModel(model string) bool {
+	info, ok := Models.Get(model)
+	return ok && info.Reasoning
+}
+
+// 依模型調整請求內容, 送出前由 Completions 與 NewJsonlFile 自動呼叫
+//
+// 依模型目錄 Models 調整, 不在目錄中的模型不調整
+// 推理模型將 MaxTokens 轉為 MaxCompletionTokens
+// 系統提示依 ModelInfo.InstructionRole 轉為 developer 訊息, 不支援系統提示的模型轉為 user 訊息
+// 轉換時建立新的訊息列表, 不修改原本的訊息
+func (self *ChatCompletionRequest) NormalizeForModel() {
+	info, ok := Models.Get(self.Model)
+	if !ok {
+		return
+	}
+
+	if info.Reasoning && self.MaxTokens > 0 {
+		if self.MaxCompletionTokens == 0 {
+			self.MaxCompletionTokens = self.MaxTokens
+		}
+		self.MaxTokens = 0
+	}
+
+	if info.InstructionRole == "" || info.InstructionRole == MessageContentRole_System {
+		return
+	}
+
+	var messages Messages
+	for i, message := range self.Messages {
+		converted := convertInstructionMessage(message, info.InstructionRole)
+		if converted == nil {
+			continue
+		}
+
+		if messages == nil {
+			messages = append(Messages(nil), self.Messages...)
+		}
+		messages[i] = converted
+	}
+	if messages != nil {
+		self.Messages = messages
+	}
+}
+
+// 將系統提示 (含開發者指示) 轉為指定 role 的訊息, 不需轉換時回傳 nil
+func convertInstructionMessage(message IMessage, role string) IMessage {
+	var name string
+	var content IContent
+	switch m := message.(type) {
+	case *SystemMessage:
+		name, content = m.Name, m.Content
+	case SystemMessage:
+		name, content = m.Name, m.Content
+	case *DeveloperMessage:
+		if role == MessageContentRole_Developer {
+			return nil
+		}
+		name, content = m.Name, m.Content
+	case DeveloperMessage:
+		if role == MessageContentRole_Developer {
+			return nil
+		}
+		name, content = m.Name, m.Content
+	default:
+		return nil
+	}
+
+	switch role {
+	case MessageContentRole_Developer:
+		return &DeveloperMessage{Name: name, Role: role, Content: content}
+	case MessageContentRole_User:
+		return &UserMessage{Name: name, Role: role, Content: content}
+	}
+	return nil
+}
+
+// 將指標形式的 tool_choice 轉為值, 方便以型別判斷
+func derefToolChoice(choice IToolChoice) IToolChoice {
+	switch c := choice.(type) {
+	case *ToolChoiceString:
+		if c != nil {
+			return *c
+		}
+	case *ToolChoiceObject:
+		if c != nil {
+			return *c
+		}
+	}
+	return choice
+}
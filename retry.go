@@ -0,0 +1,121 @@
+package gptapi
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"net/http"
+	"time"
+)
+
+// 重試策略介面
+type IRetryPolicy interface {
+	// 第 attempt 次(從 1 起算)請求失敗後是否重試, 以及重試前的等待時間
+	//
+	// @idempotent 請求重送是否不會造成重複建立資源 EX: 上傳檔案, 建立批次為 false
+	Retry(attempt int, idempotent bool, err error) (time.Duration, bool)
+}
+
+// 指數退避重試策略
+type RetryPolicy struct {
+	MaxAttempts    int           // 最大請求次數(含第一次), 小於等於 1 表示不重試
+	InitialBackoff time.Duration // 第一次重試前的等待時間
+	MaxBackoff     time.Duration // 退避等待時間上限, 伺服器指定的 Retry-After 不受此限制
+	Multiplier     float64       // 每次重試等待時間的倍率
+	Jitter         float64       // 隨機抖動比例 0~1, 避免多個請求同時重試
+}
+
+// 預設重試策略 最多請求 3 次, 等待 0.5s 起每次加倍, 上限 8s
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts:    3,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     8 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+// 不重試策略
+func NoRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{MaxAttempts: 1}
+}
+
+func (self *RetryPolicy) Retry(attempt int, idempotent bool, err error) (time.Duration, bool) {
+	if attempt >= self.MaxAttempts || !isRetryable(idempotent, err) {
+		return 0, false
+	}
+
+	wait := self.backoff(attempt)
+	if apiErr, ok := asAPIError(err); ok && apiErr.RetryAfter > wait {
+		wait = apiErr.RetryAfter
+	}
+
+	return wait, true
+}
+
+// 第 attempt 次失敗後的退避時間
+func (self *RetryPolicy) backoff(attempt int) time.Duration {
+	multiplier := self.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	wait := float64(self.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if self.MaxBackoff > 0 && wait > float64(self.MaxBackoff) {
+		wait = float64(self.MaxBackoff)
+	}
+
+	if self.Jitter > 0 {
+		wait += wait * self.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	return time.Duration(wait)
+}
+
+// 判斷錯誤是否可重試
+//
+// 429 代表請求未被處理, 不論是否冪等皆可重試 (額度用盡除外)
+// 5xx, 408, 409 與傳輸錯誤時請求可能已被處理, 只重試冪等請求
+func isRetryable(idempotent bool, err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	apiErr, ok := asAPIError(err)
+	if !ok {
+		return idempotent
+	}
+
+	switch {
+	case apiErr.HTTPStatusCode == http.StatusTooManyRequests:
+		return apiErr.Code != "insufficient_quota"
+	case apiErr.HTTPStatusCode == http.StatusRequestTimeout,
+		apiErr.HTTPStatusCode == http.StatusConflict,
+		apiErr.HTTPStatusCode >= http.StatusInternalServerError:
+		return idempotent
+	}
+
+	return false
+}
+
+// 等待指定時間, ctx 結束時提前返回 ctx.Err()
+func sleepContext(ctx context.Context, wait time.Duration) error {
+	if wait <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,53 @@
+package gptapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRetryServerErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(c *Client) error
+		want int
+	}{
+		{"completions", func(c *Client) error {
+			_, err := c.Completions(*NewChatCompletionRequest("gpt-4o").WithUser("hi"))
+			return err
+		}, 3},
+		{"completions stream", func(c *Client) error {
+			_, err := c.CompletionsStream(*NewChatCompletionRequest("gpt-4o").WithUser("hi"))
+			return err
+		}, 3},
+		{"list files", func(c *Client) error {
+			_, err := c.ListFile()
+			return err
+		}, 3},
+		{"create batch", func(c *Client) error {
+			_, err := c.CreateBatch("file-1")
+			return err
+		}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				w.WriteHeader(http.StatusInternalServerError)
+				_, _ = w.Write([]byte(`{"error":{"message":"server error"}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(WithAPIKey("key"), WithBaseURL(server.URL), WithRetryPolicy(&RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond}))
+			if err := tt.call(client); err == nil {
+				t.Fatal("error = nil, want server error")
+			}
+			if attempts != tt.want {
+				t.Errorf("attempts = %d, want %d", attempts, tt.want)
+			}
+		})
+	}
+}
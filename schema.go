@@ -0,0 +1,441 @@
+package gptapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	jsonMarshalerType  = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	rawJSONMessageType = reflect.TypeOf(json.RawMessage{})
+)
+
+// 由 Go struct 產生 FunctionParameters
+//
+// 欄位名稱依 json tag, 欄位說明與限制由 jsonschema tag 設定
+//
+//	type WeatherArgs struct {
+//		City string   `json:"city" jsonschema:"description=城市名稱"`
+//		Unit string   `json:"unit,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+//		Days int      `json:"days" jsonschema:"minimum=1,maximum=7"`
+//		Tags []string `json:"tags" jsonschema:"optional"`
+//	}
+//
+// 支援的 jsonschema 設定: description, enum (以 | 分隔), format, minimum, maximum, optional
+// 說明含有逗號時可改用 jsonschema_description tag
+// json tag 含 omitempty 或 jsonschema 含 optional 的欄位視為非必要欄位, 指標欄位可為 null
+// 遞迴型別放入 $defs 並以 $ref 參照
+//
+// @strict 為 true 時依 strict 模式規則, 所有欄位皆列為必要, 非必要欄位改為可為 null
+func NewFunctionParametersFor(v interface{}, strict bool) (FunctionParameters, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return FunctionParameters{}, errors.New("[NewFunctionParametersFor] Error nil type")
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return FunctionParameters{}, fmt.Errorf("[NewFunctionParametersFor] Error root type must be struct: %s", t)
+	}
+
+	generator := &schemaGenerator{
+		strict:    strict,
+		root:      t,
+		visiting:  make(map[reflect.Type]bool),
+		recursive: make(map[reflect.Type]bool),
+		defs:      make(map[string]Schema),
+	}
+	object, err := generator.schemaOf(t)
+	if err != nil {
+		return FunctionParameters{}, fmt.Errorf("[NewFunctionParametersFor] Error %v", err)
+	}
+
+	if len(generator.defs) > 0 {
+		object.Defs = generator.defs
+	}
+	return object, nil
+}
+
+// jsonschema tag 設定
+type schemaTag struct {
+	description string
+	enum        []string
+	format      string
+	minimum     *float64
+	maximum     *float64
+	optional    bool
+}
+
+// 解析欄位的 jsonschema 與 jsonschema_description tag
+func parseSchemaTag(field reflect.StructField) (schemaTag, error) {
+	tag := schemaTag{}
+	for _, option := range strings.Split(field.Tag.Get("jsonschema"), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "":
+		case "description":
+			tag.description = value
+		case "enum":
+			tag.enum = strings.Split(value, "|")
+		case "format":
+			tag.format = value
+		case "minimum", "maximum":
+			number, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return tag, fmt.Errorf("invalid %s: %q", key, value)
+			}
+			if key == "minimum" {
+				tag.minimum = &number
+			} else {
+				tag.maximum = &number
+			}
+		case "optional":
+			tag.optional = true
+		default:
+			return tag, fmt.Errorf("unknown jsonschema option: %q", key)
+		}
+	}
+
+	if description := field.Tag.Get("jsonschema_description"); description != "" {
+		tag.description = description
+	}
+
+	return tag, nil
+}
+
+// 以反射產生參數定義
+type schemaGenerator struct {
+	strict    bool                  // 是否依 strict 模式規則產生
+	root      reflect.Type          // 根節點型別, 遞迴參照時以 "#" 表示
+	visiting  map[reflect.Type]bool // 處理中的 struct, 用於偵測遞迴型別
+	recursive map[reflect.Type]bool // 被遞迴參照的 struct, 需放入 $defs
+	defs      map[string]Schema     // 遞迴型別定義
+}
+
+// 產生型別對應的參數定義, 指標型別可為 null
+func (self *schemaGenerator) schemaOf(t reflect.Type) (Schema, error) {
+	if t.Kind() == reflect.Pointer {
+		param, err := self.schemaOf(t.Elem())
+		if err != nil {
+			return Schema{}, err
+		}
+		return nullableSchema(param), nil
+	}
+
+	if t == timeType {
+		return Schema{Type: "string", Format: "date-time"}, nil
+	}
+	if t == rawJSONMessageType || t.Implements(jsonMarshalerType) {
+		return Schema{}, fmt.Errorf("not support custom json type: %s", t)
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{Type: "string"}, nil
+	case reflect.Bool:
+		return Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{Type: "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return Schema{Type: "number"}, nil
+	case reflect.Slice, reflect.Array:
+		// []byte 以 base64 字串編碼
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return Schema{Type: "string"}, nil
+		}
+
+		items, err := self.schemaOf(t.Elem())
+		if err != nil {
+			return Schema{}, err
+		}
+		return Schema{Type: "array", Items: &items}, nil
+	case reflect.Struct:
+		return self.objectOf(t)
+	}
+
+	return Schema{}, fmt.Errorf("not support type: %s", t)
+}
+
+// 產生 struct 對應的物件定義
+//
+// 遞迴型別放入 $defs 並以 $ref 參照, 根節點型別以 "#" 參照
+func (self *schemaGenerator) objectOf(t reflect.Type) (Schema, error) {
+	if _, exists := self.defs[defName(t)]; exists && self.recursive[t] {
+		return Schema{Ref: "#/$defs/" + defName(t)}, nil
+	}
+	if self.visiting[t] {
+		if t == self.root {
+			return Schema{Ref: "#"}, nil
+		}
+		self.recursive[t] = true
+		return Schema{Ref: "#/$defs/" + defName(t)}, nil
+	}
+	self.visiting[t] = true
+	defer delete(self.visiting, t)
+
+	object := Schema{
+		Type:                 "object",
+		Properties:           make(map[string]Schema),
+		Required:             []string{},
+		AdditionalProperties: false,
+	}
+
+	if err := self.collectFields(t, &object); err != nil {
+		return Schema{}, err
+	}
+
+	if self.recursive[t] {
+		name := defName(t)
+		if _, exists := self.defs[name]; exists {
+			return Schema{}, fmt.Errorf("duplicate definition name: %s", name)
+		}
+		self.defs[name] = object
+		return Schema{Ref: "#/$defs/" + name}, nil
+	}
+	return object, nil
+}
+
+// $defs 中的定義名稱
+func defName(t reflect.Type) string {
+	return schemaNameInvalidChars.ReplaceAllString(t.Name(), "_")
+}
+
+// 收集 struct 欄位, 匿名嵌入的 struct 欄位展開至同一層
+func (self *schemaGenerator) collectFields(t reflect.Type, object *Schema) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, omitempty, skip := jsonFieldName(field)
+		if skip {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := self.collectFields(embedded, object); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		param, optional, err := self.fieldOf(field)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %v", t.Name(), field.Name, err)
+		}
+		optional = optional || omitempty
+
+		// strict 模式所有欄位皆必須列為必要, 以 null 表示未提供
+		if optional && self.strict {
+			if param.Type != "" {
+				description := param.Description
+				param.Description = ""
+				param = nullableSchema(param)
+				param.Description = description
+			}
+			optional = false
+		}
+
+		object.Properties[name] = param
+		if !optional {
+			object.Required = append(object.Required, name)
+		}
+	}
+
+	return nil
+}
+
+// 產生欄位對應的參數定義並套用 jsonschema tag
+func (self *schemaGenerator) fieldOf(field reflect.StructField) (Schema, bool, error) {
+	tag, err := parseSchemaTag(field)
+	if err != nil {
+		return Schema{}, false, err
+	}
+
+	param, err := self.schemaOf(field.Type)
+	if err != nil {
+		return Schema{}, false, err
+	}
+
+	// 限制條件套用在非 null 的定義上
+	target := &param
+	if len(param.AnyOf) > 0 {
+		target = &param.AnyOf[0]
+	}
+
+	if len(tag.enum) > 0 {
+		baseType := field.Type
+		for baseType.Kind() == reflect.Pointer {
+			baseType = baseType.Elem()
+		}
+		for _, value := range tag.enum {
+			if _, err := parseEnumValue(baseType, value); err != nil {
+				return Schema{}, false, err
+			}
+			target.Enum = append(target.Enum, value)
+		}
+	}
+	if tag.format != "" {
+		target.Format = tag.format
+	}
+	if tag.minimum != nil {
+		target.Minimum = tag.minimum
+	}
+	if tag.maximum != nil {
+		target.Maximum = tag.maximum
+	}
+	param.Description = tag.description
+
+	return param, tag.optional, nil
+}
+
+// 依欄位型別轉換 enum 值
+func parseEnumValue(t reflect.Type, value string) (interface{}, error) {
+	switch t.Kind() {
+	case reflect.String:
+		return value, nil
+	case reflect.Bool:
+		return strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(value, 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(value, 64)
+	}
+
+	return nil, fmt.Errorf("enum not support type: %s", t)
+}
+
+// 解析 json tag 取得欄位名稱
+//
+// @skip 欄位標記為 "-" 時為 true
+func jsonFieldName(field reflect.StructField) (name string, omitempty bool, skip bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false, true
+	}
+
+	name, options, _ := strings.Cut(tag, ",")
+	for _, option := range strings.Split(options, ",") {
+		if option == "omitempty" {
+			omitempty = true
+		}
+	}
+	return name, omitempty, false
+}
+
+// 允許 null 的定義
+func nullableSchema(param Schema) Schema {
+	if len(param.AnyOf) > 0 {
+		return param
+	}
+
+	return Schema{
+		AnyOf: []Schema{param, {Type: "null"}},
+	}
+}
+
+// 依 Type 轉換 enum 值, integer 與 number 轉為數值, boolean 轉為布林值, 無法轉換時維持字串
+func (self Schema) EnumValues() []interface{} {
+	if len(self.Enum) == 0 {
+		return nil
+	}
+
+	values := make([]interface{}, len(self.Enum))
+	for i, text := range self.Enum {
+		var value interface{} = text
+		switch self.Type {
+		case "integer":
+			if number, err := strconv.ParseInt(text, 10, 64); err == nil {
+				value = number
+			}
+		case "number":
+			if number, err := strconv.ParseFloat(text, 64); err == nil {
+				value = number
+			}
+		case "boolean":
+			if b, err := strconv.ParseBool(text); err == nil {
+				value = b
+			}
+		}
+		values[i] = value
+	}
+	return values
+}
+
+// 以 EnumValues 輸出 enum
+//
+// 物件定義一律輸出 properties, required 與 additionalProperties, 沒有參數的 strict 工具也需要這些欄位
+func (self Schema) MarshalJSON() ([]byte, error) {
+	type alias Schema
+	if self.Type != "object" {
+		return json.Marshal(struct {
+			alias
+			Enum []interface{} `json:"enum,omitempty"`
+		}{
+			alias: alias(self),
+			Enum:  self.EnumValues(),
+		})
+	}
+
+	properties, required := self.Properties, self.Required
+	if properties == nil {
+		properties = map[string]Schema{}
+	}
+	if required == nil {
+		required = []string{}
+	}
+	return json.Marshal(struct {
+		alias
+		Enum                 []interface{}     `json:"enum,omitempty"`
+		Properties           map[string]Schema `json:"properties"`
+		Required             []string          `json:"required"`
+		AdditionalProperties bool              `json:"additionalProperties"`
+	}{
+		alias:                alias(self),
+		Enum:                 self.EnumValues(),
+		Properties:           properties,
+		Required:             required,
+		AdditionalProperties: self.AdditionalProperties,
+	})
+}
+
+// 解析 JSON Schema, enum 的數值與布林值以字串保存
+func (self *Schema) UnmarshalJSON(data []byte) error {
+	type alias Schema
+	aux := struct {
+		*alias
+		Enum []json.RawMessage `json:"enum,omitempty"`
+	}{alias: (*alias)(self)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	self.Enum = nil
+	for _, raw := range aux.Enum {
+		var text string
+		if err := json.Unmarshal(raw, &text); err != nil {
+			text = string(raw)
+		}
+		self.Enum = append(self.Enum, text)
+	}
+	return nil
+}
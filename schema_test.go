@@ -0,0 +1,171 @@
+package gptapi
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+type schemaTestWeather struct {
+	City string    `json:"city" jsonschema:"description=City name"`
+	Unit string    `json:"unit,omitempty" jsonschema:"enum=celsius|fahrenheit"`
+	Days int       `json:"days" jsonschema:"minimum=1,maximum=7"`
+	Note *string   `json:"note"`
+	At   time.Time `json:"at"`
+	Skip string    `json:"-"`
+}
+
+type schemaTestNode struct {
+	Value    string           `json:"value"`
+	Children []schemaTestNode `json:"children"`
+}
+
+type schemaTestList struct {
+	Head *schemaTestItem `json:"head"`
+}
+
+type schemaTestItem struct {
+	Name string          `json:"name"`
+	Next *schemaTestItem `json:"next"`
+}
+
+func TestNewFunctionParametersFor(t *testing.T) {
+	tests := []struct {
+		name   string
+		v      interface{}
+		strict bool
+		want   string
+	}{
+		{
+			name: "tags and pointer",
+			v:    schemaTestWeather{},
+			want: `{"type":"object","properties":{"at":{"type":"string","format":"date-time"},"city":{"type":"string","description":"City name"},"days":{"type":"integer","minimum":1,"maximum":7},"note":{"anyOf":[{"type":"string"},{"type":"null"}]},"unit":{"type":"string","enum":["celsius","fahrenheit"]}},"required":["city","days","note","at"],"additionalProperties":false}`,
+		},
+		{
+			name:   "strict optional becomes nullable",
+			v:      schemaTestWeather{},
+			strict: true,
+			want:   `{"type":"object","properties":{"at":{"type":"string","format":"date-time"},"city":{"type":"string","description":"City name"},"days":{"type":"integer","minimum":1,"maximum":7},"note":{"anyOf":[{"type":"string"},{"type":"null"}]},"unit":{"anyOf":[{"type":"string","enum":["celsius","fahrenheit"]},{"type":"null"}]}},"required":["city","unit","days","note","at"],"additionalProperties":false}`,
+		},
+		{
+			name:   "recursive root",
+			v:      &schemaTestNode{},
+			strict: true,
+			want:   `{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#"}},"value":{"type":"string"}},"required":["value","children"],"additionalProperties":false}`,
+		},
+		{
+			name:   "recursive pointer in defs",
+			v:      schemaTestList{},
+			strict: true,
+			want:   `{"$defs":{"schemaTestItem":{"type":"object","properties":{"name":{"type":"string"},"next":{"anyOf":[{"$ref":"#/$defs/schemaTestItem"},{"type":"null"}]}},"required":["name","next"],"additionalProperties":false}},"type":"object","properties":{"head":{"anyOf":[{"$ref":"#/$defs/schemaTestItem"},{"type":"null"}]}},"required":["head"],"additionalProperties":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params, err := NewFunctionParametersFor(tt.v, tt.strict)
+			if err != nil {
+				t.Fatalf("NewFunctionParametersFor: %v", err)
+			}
+			js, err := json.Marshal(params)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(js) != tt.want {
+				t.Errorf("schema = %s\nwant %s", js, tt.want)
+			}
+			if tt.strict {
+				if err := params.ValidateStrict(); err != nil {
+					t.Errorf("ValidateStrict: %v", err)
+				}
+			}
+
+			var decoded Schema
+			if err := json.Unmarshal(js, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if again, _ := json.Marshal(decoded); string(again) != tt.want {
+				t.Errorf("round trip = %s\nwant %s", again, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewFunctionParametersForErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"nil", nil, "nil type"},
+		{"not struct", 1, "root type must be struct"},
+		{"map field", struct{ M map[string]int }{}, "not support type"},
+		{"chan field", struct{ C chan int }{}, "not support type"},
+		{"raw json field", struct{ R json.RawMessage }{}, "not support custom json type"},
+		{"invalid enum", struct {
+			N int `json:"n" jsonschema:"enum=1|two"`
+		}{}, "two"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewFunctionParametersFor(tt.v, false)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateStrict(t *testing.T) {
+	str := Schema{Type: "string"}
+	object := func(properties map[string]Schema) Schema {
+		required := []string{}
+		for name := range properties {
+			required = append(required, name)
+		}
+		return Schema{Type: "object", Properties: properties, Required: required}
+	}
+	deep := object(map[string]Schema{"v": str})
+	for i := 0; i < strictSchemaDepthLimit; i++ {
+		deep = object(map[string]Schema{"child": deep})
+	}
+
+	tests := []struct {
+		name   string
+		schema Schema
+		want   string // 空字串表示應通過檢查
+	}{
+		{"valid", object(map[string]Schema{"a": str, "b": {AnyOf: []Schema{str, {Type: "null"}}}}), ""},
+		{"root not object", str, "root type must be object"},
+		{"root anyOf", Schema{AnyOf: []Schema{object(nil)}}, "root must not be anyOf"},
+		{"additional properties", Schema{Type: "object", Properties: map[string]Schema{}, AdditionalProperties: true}, "additionalProperties must be false"},
+		{"optional property", Schema{Type: "object", Properties: map[string]Schema{"a": str}}, `property "a" must be required`},
+		{"undefined required", Schema{Type: "object", Properties: map[string]Schema{}, Required: []string{"x"}}, `required property "x" is not defined`},
+		{"array without items", object(map[string]Schema{"a": {Type: "array"}}), "array must define items"},
+		{"unsupported format", object(map[string]Schema{"a": {Type: "string", Format: "uri"}}), `unsupported format "uri"`},
+		{"unsupported type", object(map[string]Schema{"a": {Type: "any"}}), `unsupported type "any"`},
+		{"default", object(map[string]Schema{"a": {Type: "string", Default: "x"}}), "default is not supported"},
+		{"anyOf with type", object(map[string]Schema{"a": {Type: "string", AnyOf: []Schema{str}}}), "type must be empty when using anyOf"},
+		{"undefined ref", object(map[string]Schema{"a": {Ref: "#/$defs/Missing"}}), `undefined $ref "#/$defs/Missing"`},
+		{"external ref", object(map[string]Schema{"a": {Ref: "other.json"}}), `unsupported $ref`},
+		{"nested defs", object(map[string]Schema{"a": {Type: "object", Properties: map[string]Schema{}, Required: []string{}, Defs: map[string]Schema{"x": str}}}), "$defs only allowed at root"},
+		{"too deep", deep, "nesting too deep"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.schema.ValidateStrict()
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("ValidateStrict = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ValidateStrict = %v, want containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,155 @@
+package gptapi
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// strict 模式限制
+const (
+	strictSchemaPropertiesLimit = 5000 // 物件欄位總數上限
+	strictSchemaDepthLimit      = 10   // 物件巢狀層數上限
+	strictSchemaEnumLimit       = 1000 // enum 值總數上限
+)
+
+var (
+	// strict 模式支援的資料類型
+	strictSchemaTypes = []string{"string", "number", "integer", "boolean", "object", "array", "null"}
+	// strict 模式支援的字串格式
+	strictSchemaFormats = []string{"date-time", "time", "date", "duration", "email", "hostname", "ipv4", "ipv6", "uuid"}
+)
+
+// 檢查 schema 是否符合 OpenAI strict 模式限制, 回傳所有發現的問題
+//
+// 根節點需為 "object", 所有物件需列出全部欄位為必要且 additionalProperties 為 false
+func (self *Schema) ValidateStrict() error {
+	validator := &strictSchemaValidator{root: self}
+
+	if self.Type != "object" {
+		validator.addf("$", "root type must be object, got %q", self.Type)
+	}
+	if len(self.AnyOf) > 0 {
+		validator.addf("$", "root must not be anyOf")
+	}
+
+	validator.walk("$", self, 0)
+	for name, def := range self.Defs {
+		def := def
+		validator.walk("$.$defs."+name, &def, 0)
+	}
+
+	if validator.properties > strictSchemaPropertiesLimit {
+		validator.addf("$", "too many properties: %d, limit: %d", validator.properties, strictSchemaPropertiesLimit)
+	}
+	if validator.enums > strictSchemaEnumLimit {
+		validator.addf("$", "too many enum values: %d, limit: %d", validator.enums, strictSchemaEnumLimit)
+	}
+
+	return errors.Join(validator.errs...)
+}
+
+// strict 模式檢查狀態
+type strictSchemaValidator struct {
+	root       *Schema
+	properties int // 已檢查的物件欄位數量
+	enums      int // 已檢查的 enum 值數量
+	errs       []error
+}
+
+func (self *strictSchemaValidator) addf(path, format string, args ...interface{}) {
+	self.errs = append(self.errs, fmt.Errorf("[ValidateStrict] Error %s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+// 遞迴檢查節點
+//
+// @depth 目前的物件巢狀層數
+func (self *strictSchemaValidator) walk(path string, schema *Schema, depth int) {
+	if schema.Ref != "" {
+		self.checkRef(path, schema.Ref)
+		return
+	}
+
+	if schema != self.root && len(schema.Defs) > 0 {
+		self.addf(path, "$defs only allowed at root")
+	}
+	if schema.Default != nil {
+		self.addf(path, "default is not supported")
+	}
+	self.enums += len(schema.Enum)
+
+	if len(schema.AnyOf) > 0 {
+		if schema.Type != "" {
+			self.addf(path, "type must be empty when using anyOf")
+		}
+		for i := range schema.AnyOf {
+			self.walk(fmt.Sprintf("%s.anyOf[%d]", path, i), &schema.AnyOf[i], depth)
+		}
+		return
+	}
+
+	if !slices.Contains(strictSchemaTypes, schema.Type) {
+		self.addf(path, "unsupported type %q", schema.Type)
+		return
+	}
+
+	if schema.Format != "" && !slices.Contains(strictSchemaFormats, schema.Format) {
+		self.addf(path, "unsupported format %q", schema.Format)
+	}
+
+	switch schema.Type {
+	case "object":
+		self.checkObject(path, schema, depth+1)
+	case "array":
+		if schema.Items == nil {
+			self.addf(path, "array must define items")
+		} else {
+			self.walk(path+".items", schema.Items, depth)
+		}
+	}
+}
+
+// 檢查物件節點
+func (self *strictSchemaValidator) checkObject(path string, schema *Schema, depth int) {
+	if depth > strictSchemaDepthLimit {
+		self.addf(path, "nesting too deep: %d, limit: %d", depth, strictSchemaDepthLimit)
+		return
+	}
+
+	if schema.AdditionalProperties {
+		self.addf(path, "additionalProperties must be false")
+	}
+
+	self.properties += len(schema.Properties)
+	for name, property := range schema.Properties {
+		if !slices.Contains(schema.Required, name) {
+			self.addf(path, "property %q must be required, use anyOf with null for optional", name)
+		}
+
+		property := property
+		self.walk(path+"."+name, &property, depth)
+	}
+
+	for _, name := range schema.Required {
+		if _, ok := schema.Properties[name]; !ok {
+			self.addf(path, "required property %q is not defined", name)
+		}
+	}
+}
+
+// 檢查 $ref 是否能對應到根節點或 $defs
+func (self *strictSchemaValidator) checkRef(path, ref string) {
+	if ref == "#" {
+		return
+	}
+
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		self.addf(path, "unsupported $ref %q", ref)
+		return
+	}
+	if _, ok := self.root.Defs[name]; !ok {
+		self.addf(path, "undefined $ref %q", ref)
+	}
+}
@@ -0,0 +1,195 @@
+package gptapi
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// 串流結束標記
+const streamDoneData = "[DONE]"
+
+// Completions 串流回應
+//
+// 以 Next 逐一讀取片段, 讀取結束後以 Err 檢查錯誤, 使用完畢需呼叫 Close
+//
+//	stream, err := client.CompletionsStream(req)
+//	if err != nil {
+//		return err
+//	}
+//	defer stream.Close()
+//	for stream.Next() {
+//		chunk := stream.Current()
+//	}
+//	return stream.Err()
+type Stream struct {
+	ctx     context.Context
+	resp    *http.Response
+	decoder *chunkDecoder
+	current ChatCompletionChunk
+	err     error
+	done    bool
+}
+
+func newStream(ctx context.Context, resp *http.Response) *Stream {
+	return &Stream{
+		ctx:     ctx,
+		resp:    resp,
+		decoder: newChunkDecoder(resp),
+	}
+}
+
+// 讀取下一個片段, 串流結束或發生錯誤時回傳 false
+func (self *Stream) Next() bool {
+	if self.done {
+		return false
+	}
+
+	chunk, err := self.decoder.Recv()
+	if err != nil {
+		if err != io.EOF {
+			self.err = err
+			if self.ctx.Err() != nil {
+				self.err = self.ctx.Err()
+			}
+		}
+		self.Close()
+		return false
+	}
+
+	self.current = chunk
+	return true
+}
+
+// 目前讀取到的片段
+func (self *Stream) Current() ChatCompletionChunk {
+	return self.current
+}
+
+// 讀取過程中發生的錯誤, 正常結束時為 nil
+func (self *Stream) Err() error {
+	return self.err
+}
+
+// 關閉串流, 可提前結束讀取, 重複呼叫無影響
+func (self *Stream) Close() error {
+	if self.done {
+		return nil
+	}
+	self.done = true
+	return self.resp.Body.Close()
+}
+
+// server-sent events 事件
+type sseEvent struct {
+	Event string // 事件名稱, 未指定時為空字串
+	Data  []byte // 事件資料, 多行 data 以 '\n' 串接
+}
+
+// server-sent events 解碼器
+type sseDecoder struct {
+	reader *bufio.Reader
+}
+
+func newSSEDecoder(r io.Reader) *sseDecoder {
+	return &sseDecoder{reader: bufio.NewReader(r)}
+}
+
+// 讀取下一個事件, 資料讀完時回傳 io.EOF
+func (self *sseDecoder) Next() (*sseEvent, error) {
+	event := &sseEvent{}
+	hasData := false
+
+	for {
+		line, err := self.reader.ReadBytes('\n')
+		if err != nil && (err != io.EOF || len(line) == 0) {
+			if err == io.EOF && hasData {
+				return event, nil
+			}
+			return nil, err
+		}
+		line = bytes.TrimRight(line, "\r\n")
+
+		// 空行代表事件結束
+		if len(line) == 0 {
+			if hasData {
+				return event, nil
+			}
+			event.Event = ""
+			continue
+		}
+
+		// 註解行
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value, _ := bytes.Cut(line, []byte{':'})
+		value = bytes.TrimPrefix(value, []byte{' '})
+
+		switch string(field) {
+		case "event":
+			event.Event = string(value)
+		case "data":
+			if hasData {
+				event.Data = append(event.Data, '\n')
+			}
+			event.Data = append(event.Data, value...)
+			hasData = true
+		}
+	}
+}
+
+// Completions 串流片段解碼器
+type chunkDecoder struct {
+	sse  *sseDecoder
+	resp *http.Response
+}
+
+func newChunkDecoder(resp *http.Response) *chunkDecoder {
+	return &chunkDecoder{
+		sse:  newSSEDecoder(resp.Body),
+		resp: resp,
+	}
+}
+
+// 讀取下一個片段
+//
+// 收到 [DONE] 或資料讀完時回傳 io.EOF, 串流中的錯誤事件轉為 *APIError
+func (self *chunkDecoder) Recv() (ChatCompletionChunk, error) {
+	for {
+		event, err := self.sse.Next()
+		if err != nil {
+			return ChatCompletionChunk{}, err
+		}
+
+		data := bytes.TrimSpace(event.Data)
+		if len(data) == 0 {
+			continue
+		}
+		if string(data) == streamDoneData {
+			return ChatCompletionChunk{}, io.EOF
+		}
+
+		errRes := ErrorResponse{}
+		if err := json.Unmarshal(data, &errRes); err == nil && (errRes.Error.Message != "" || event.Event == "error") {
+			return ChatCompletionChunk{}, &APIError{
+				HTTPStatusCode: self.resp.StatusCode,
+				ErrorDetail:    errRes.Error,
+				RequestID:      self.resp.Header.Get("x-request-id"),
+				Body:           data,
+			}
+		}
+
+		chunk := ChatCompletionChunk{}
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return ChatCompletionChunk{}, fmt.Errorf("無法解析串流片段: %v", err)
+		}
+
+		return chunk, nil
+	}
+}
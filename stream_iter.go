@@ -0,0 +1,28 @@
+//go:build go1.23
+
+package gptapi
+
+import "iter"
+
+// 以 range 逐一讀取串流片段, 提前 break 會關閉串流
+//
+//	for chunk, err := range stream.All() {
+//		if err != nil {
+//			return err
+//		}
+//	}
+func (self *Stream) All() iter.Seq2[ChatCompletionChunk, error] {
+	return func(yield func(ChatCompletionChunk, error) bool) {
+		defer self.Close()
+
+		for self.Next() {
+			if !yield(self.Current(), nil) {
+				return
+			}
+		}
+
+		if err := self.Err(); err != nil {
+			yield(ChatCompletionChunk{}, err)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package gptapi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSSEDecoder(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		events []sseEvent
+	}{
+		{"single", "data: a\n\n", []sseEvent{{Data: []byte("a")}}},
+		{"crlf", "data: a\r\n\r\ndata: b\r\n\r\n", []sseEvent{{Data: []byte("a")}, {Data: []byte("b")}}},
+		{"multi-line data", "data: {\"a\":\ndata: 1}\n\n", []sseEvent{{Data: []byte("{\"a\":\n1}")}}},
+		{"event name", "event: error\ndata: x\n\n", []sseEvent{{Event: "error", Data: []byte("x")}}},
+		{"comments and keep-alive", ": keep-alive\n\n: ping\ndata: a\n\n\n\n", []sseEvent{{Data: []byte("a")}}},
+		{"no space after colon", "data:a\n\n", []sseEvent{{Data: []byte("a")}}},
+		{"eof without blank line", "data: a", []sseEvent{{Data: []byte("a")}}},
+		{"event without data", "event: ping\n\ndata: a\n\n", []sseEvent{{Data: []byte("a")}}},
+		{"ignored fields", "id: 1\nretry: 100\ndata: a\n\n", []sseEvent{{Data: []byte("a")}}},
+		{"empty", "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decoder := newSSEDecoder(strings.NewReader(tt.input))
+			var events []sseEvent
+			for {
+				event, err := decoder.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("Next: %v", err)
+				}
+				events = append(events, *event)
+			}
+			if !reflect.DeepEqual(events, tt.events) {
+				t.Errorf("events = %q, want %q", events, tt.events)
+			}
+		})
+	}
+}
+
+// 以字串內容建立串流
+func newTestStream(body string) *Stream {
+	return newStream(context.Background(), &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"X-Request-Id": []string{"req_1"}},
+		Body:       io.NopCloser(strings.NewReader(body)),
+	})
+}
+
+func TestStream(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		contents []string
+		errMsg   string
+	}{
+		{
+			name:     "done",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"b\"}}]}\n\ndata: [DONE]\n\n",
+			contents: []string{"a", "b"},
+		},
+		{
+			name:     "data after done is ignored",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\ndata: [DONE]\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"b\"}}]}\n\n",
+			contents: []string{"a"},
+		},
+		{
+			name:     "eof without done",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n",
+			contents: []string{"a"},
+		},
+		{
+			name:     "keep-alive comments",
+			body:     ": OPENROUTER PROCESSING\n\ndata: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\n: ping\n\ndata: [DONE]\n\n",
+			contents: []string{"a"},
+		},
+		{
+			name:     "multi-line data",
+			body:     "data: {\"choices\":[{\"delta\":\ndata: {\"content\":\"a\"}}]}\n\ndata: [DONE]\n\n",
+			contents: []string{"a"},
+		},
+		{
+			name:     "error payload",
+			body:     "data: {\"choices\":[{\"delta\":{\"content\":\"a\"}}]}\n\ndata: {\"error\":{\"message\":\"overloaded\",\"type\":\"server_error\"}}\n\n",
+			contents: []string{"a"},
+			errMsg:   "overloaded",
+		},
+		{
+			name:   "error event",
+			body:   "event: error\ndata: {\"error\":{\"message\":\"bad\"}}\n\n",
+			errMsg: "bad",
+		},
+		{
+			name:   "invalid json",
+			body:   "data: {not json}\n\n",
+			errMsg: "無法解析串流片段",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stream := newTestStream(tt.body)
+			var contents []string
+			for stream.Next() {
+				for _, choice := range stream.Current().Choices {
+					contents = append(contents, choice.Delta.Content)
+				}
+			}
+
+			if !reflect.DeepEqual(contents, tt.contents) {
+				t.Errorf("contents = %q, want %q", contents, tt.contents)
+			}
+			err := stream.Err()
+			if tt.errMsg == "" {
+				if err != nil {
+					t.Errorf("Err = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.errMsg) {
+				t.Fatalf("Err = %v, want containing %q", err, tt.errMsg)
+			}
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.RequestID != "req_1" {
+				t.Errorf("RequestID = %q, want req_1", apiErr.RequestID)
+			}
+		})
+	}
+}
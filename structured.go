@@ -0,0 +1,106 @@
+package gptapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"regexp"
+)
+
+// schema 名稱不允許的字元
+var schemaNameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+
+// 一般文字輸出格式
+func NewTextResponseFormat() ResponseFormat {
+	return ResponseFormat{Type: ResponseFormatType_Text}
+}
+
+// JSON 模式輸出格式, 提示中需要求模型輸出 JSON
+func NewJSONObjectResponseFormat() ResponseFormat {
+	return ResponseFormat{Type: ResponseFormatType_JSONObject}
+}
+
+// 結構化輸出格式
+//
+// @schema JSON Schema 內容, 根節點需為 "object"
+// @strict 是否嚴格遵循 schema
+func NewJSONSchemaResponseFormat(name string, schema Schema, strict bool) ResponseFormat {
+	return ResponseFormat{
+		Type: ResponseFormatType_JSONSchema,
+		JSONSchema: &ResponseFormatJSONSchema{
+			Name:   name,
+			Schema: &schema,
+			Strict: strict,
+		},
+	}
+}
+
+// 由 Go struct 產生 strict 結構化輸出格式, schema 名稱取自型別名稱
+//
+// 欄位 tag 規則同 NewFunctionParametersFor
+// @v 型別範例 EX: MyResult{} 或 (*MyResult)(nil)
+func NewResponseFormatFor(v interface{}) (ResponseFormat, error) {
+	schema, err := NewFunctionParametersFor(v, true)
+	if err != nil {
+		return ResponseFormat{}, err
+	}
+
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	name := schemaNameInvalidChars.ReplaceAllString(t.Name(), "_")
+	if name == "" {
+		name = "response"
+	}
+	if len(name) > 64 {
+		name = name[:64]
+	}
+
+	return NewJSONSchemaResponseFormat(name, schema, true), nil
+}
+
+// 以 T 的結構要求模型輸出並解析結果
+//
+// 模型拒絕時回傳 *RefusalError, 輸出與結構不符時回傳 *SchemaMismatchError
+// 回傳的 CompletionsResponse 可用於查看 Usage 等資訊
+func CompleteInto[T any](ctx context.Context, client *Client, req ChatCompletionRequest) (*T, *CompletionsResponse, error) {
+	var zero T
+	format, err := NewResponseFormatFor(&zero)
+	if err != nil {
+		return nil, nil, err
+	}
+	req.ResponseFormat = &format
+
+	response, err := client.CompletionsContext(ctx, req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(response.Choices) == 0 {
+		return nil, response, errors.New("[CompleteInto] Error response has no choices")
+	}
+
+	choice := response.Choices[0]
+	if choice.Message.Refusal != "" {
+		return nil, response, &RefusalError{Refusal: choice.Message.Refusal}
+	}
+	if choice.FinishReason == MessageFinishType_Length {
+		return nil, response, &SchemaMismatchError{
+			Content: choice.Message.Content,
+			Err:     errors.New("output truncated by max tokens"),
+		}
+	}
+
+	result := new(T)
+	decoder := json.NewDecoder(bytes.NewReader([]byte(choice.Message.Content)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(result); err != nil {
+		return nil, response, &SchemaMismatchError{Content: choice.Message.Content, Err: err}
+	}
+
+	return result, response, nil
+}
@@ -0,0 +1,359 @@
+package gptapi
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	_ "embed"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Unicode 空白字元, Go 正規表示式的 \s 只包含 ASCII 空白
+const unicodeSpaces = `\s\x0B\x{85}\p{Z}`
+
+// 各詞彙表的分詞規則
+//
+// 原始規則中的 \s+(?!\S) 無法以 Go 正規表示式表示, 改由 splitPieces 處理
+var encodingPatterns = map[string]string{
+	Encoding_CL100K: `(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`,
+	Encoding_O200K: strings.Join([]string{
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+		`[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?`,
+		`\p{N}{1,3}`,
+		` ?[^\s\p{L}\p{N}]+[\r\n/]*`,
+		`\s*[\r\n]+`,
+		`\s+`,
+	}, "|"),
+}
+
+var (
+	encodingsMu     sync.RWMutex
+	encodings       = make(map[string]*Encoding)                 // 已註冊的詞彙表
+	encodingLoaders = make(map[string]func() (*Encoding, error)) // 首次使用時才讀取的詞彙表
+)
+
+// 內建詞彙表, 取自 tiktoken 的 .tiktoken 檔案並以 gzip 壓縮
+var (
+	//go:embed assets/cl100k_base.tiktoken.gz
+	cl100kBaseData []byte
+
+	//go:embed assets/o200k_base.tiktoken.gz
+	o200kBaseData []byte
+)
+
+// 註冊內建的 cl100k_base 與 o200k_base 詞彙表, 解壓與解析延後到首次使用時進行
+func init() {
+	for name, data := range map[string][]byte{
+		Encoding_CL100K: cl100kBaseData,
+		Encoding_O200K:  o200kBaseData,
+	} {
+		name, data := name, data
+		encodingLoaders[name] = sync.OnceValues(func() (*Encoding, error) {
+			return LoadEncoding(name, bytes.NewReader(data))
+		})
+	}
+}
+
+// BPE 詞彙表, 將文字轉為模型使用的 token
+type Encoding struct {
+	Name    string
+	pattern *regexp.Regexp
+	ranks   map[string]int // token 內容對應的 token id, id 越小越優先合併
+	decoder map[int]string
+}
+
+// 建立詞彙表
+//
+// @pattern 分詞正規表示式, 空字串時使用 name 對應的內建規則
+// @ranks token 內容對應的 token id, 需包含所有單一位元組
+func NewEncoding(name, pattern string, ranks map[string]int) (*Encoding, error) {
+	if pattern == "" {
+		var ok bool
+		if pattern, ok = encodingPatterns[name]; !ok {
+			return nil, fmt.Errorf("[NewEncoding] Error unknown encoding pattern: %s", name)
+		}
+	}
+	re, err := regexp.Compile(`\A(?:` + toUnicodeSpaces(pattern) + `)`)
+	if err != nil {
+		return nil, fmt.Errorf("[NewEncoding] Error pattern: %v", err)
+	}
+
+	decoder := make(map[int]string, len(ranks))
+	for token, rank := range ranks {
+		decoder[rank] = token
+	}
+	for b := 0; b < 256; b++ {
+		if _, ok := ranks[string([]byte{byte(b)})]; !ok {
+			return nil, fmt.Errorf("[NewEncoding] Error missing byte token: %d", b)
+		}
+	}
+
+	return &Encoding{
+		Name:    name,
+		pattern: re,
+		ranks:   ranks,
+		decoder: decoder,
+	}, nil
+}
+
+// 讀取 tiktoken 格式的詞彙表, 每行為 "base64編碼的token token_id", 可為 gzip 壓縮檔
+//
+// 套件已內含 cl100k_base 與 o200k_base, 其他詞彙表可由使用者提供後以 RegisterEncoding 註冊
+func LoadEncoding(name string, reader io.Reader) (*Encoding, error) {
+	buffered := bufio.NewReader(reader)
+	if magic, _ := buffered.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("[LoadEncoding] Error gzip: %v", err)
+		}
+		defer gz.Close()
+		reader = gz
+	} else {
+		reader = buffered
+	}
+
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		token, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("[LoadEncoding] Error line %d: invalid format", line)
+		}
+		tokenBytes, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("[LoadEncoding] Error line %d: %v", line, err)
+		}
+		id, err := strconv.Atoi(rank)
+		if err != nil {
+			return nil, fmt.Errorf("[LoadEncoding] Error line %d: %v", line, err)
+		}
+		ranks[string(tokenBytes)] = id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("[LoadEncoding] Error %v", err)
+	}
+
+	return NewEncoding(name, "", ranks)
+}
+
+// 由檔案讀取 tiktoken 格式的詞彙表並註冊, 可為 gzip 壓縮檔
+func RegisterEncodingFile(name, path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("[RegisterEncodingFile] Error %v", err)
+	}
+	defer file.Close()
+
+	encoding, err := LoadEncoding(name, file)
+	if err != nil {
+		return err
+	}
+	RegisterEncoding(encoding)
+	return nil
+}
+
+// 註冊詞彙表, 之後 CountTokens 與 EstimateTokens 會以此詞彙表精確計算 token
+func RegisterEncoding(encoding *Encoding) {
+	encodingsMu.Lock()
+	defer encodingsMu.Unlock()
+	encodings[encoding.Name] = encoding
+}
+
+// 取得已註冊的詞彙表, 內建詞彙表於首次取得時讀取
+func GetEncoding(name string) (*Encoding, bool) {
+	encodingsMu.RLock()
+	encoding, ok := encodings[name]
+	loader := encodingLoaders[name]
+	encodingsMu.RUnlock()
+	if ok || loader == nil {
+		return encoding, ok
+	}
+
+	encoding, err := loader()
+	if err != nil {
+		return nil, false
+	}
+	return encoding, true
+}
+
+// 模型使用的詞彙表名稱
+func EncodingNameForModel(model string) string {
+	for _, prefix := range []string{"gpt-4o", "chatgpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "o1", "o3", "o4"} {
+		if strings.HasPrefix(model, prefix) {
+			return Encoding_O200K
+		}
+	}
+	return Encoding_CL100K
+}
+
+// 將文字轉為 token id, 特殊 token 視為一般文字
+func (self *Encoding) Encode(text string) []int {
+	tokens := []int{}
+	for _, piece := range self.splitPieces(text) {
+		if rank, ok := self.ranks[piece]; ok {
+			tokens = append(tokens, rank)
+			continue
+		}
+		for _, part := range self.bytePairMerge(piece) {
+			tokens = append(tokens, self.ranks[part])
+		}
+	}
+	return tokens
+}
+
+// 計算文字的 token 數量
+func (self *Encoding) Count(text string) int {
+	count := 0
+	for _, piece := range self.splitPieces(text) {
+		if _, ok := self.ranks[piece]; ok {
+			count++
+			continue
+		}
+		count += len(self.bytePairMerge(piece))
+	}
+	return count
+}
+
+// 將 token id 轉回文字
+func (self *Encoding) Decode(tokens []int) (string, error) {
+	builder := strings.Builder{}
+	for _, token := range tokens {
+		text, ok := self.decoder[token]
+		if !ok {
+			return "", fmt.Errorf("[Decode] Error unknown token: %d", token)
+		}
+		builder.WriteString(text)
+	}
+	return builder.String(), nil
+}
+
+// 依分詞規則切割文字
+func (self *Encoding) splitPieces(text string) []string {
+	return splitPieces(self.pattern, text)
+}
+
+func splitPieces(pattern *regexp.Regexp, text string) []string {
+	var pieces []string
+	for len(text) > 0 {
+		loc := pattern.FindStringIndex(text)
+		end := 1
+		if loc != nil && loc[1] > 0 {
+			end = loc[1]
+		}
+
+		// 模擬 \s+(?!\S): 連續空白後接非空白時, 最後一個空白留給下一段
+		piece := text[:end]
+		if end < len(text) && isSpaces(piece) && !strings.HasSuffix(piece, "\n") && !strings.HasSuffix(piece, "\r") {
+			_, size := utf8.DecodeLastRuneInString(piece)
+			if size < len(piece) {
+				end -= size
+			}
+		}
+
+		pieces = append(pieces, text[:end])
+		text = text[end:]
+	}
+	return pieces
+}
+
+// 依 token id 順序合併位元組, 回傳合併後的各段內容
+func (self *Encoding) bytePairMerge(piece string) []string {
+	parts := make([]string, 0, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts = append(parts, piece[i:i+1])
+	}
+
+	for len(parts) > 1 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i < len(parts)-1; i++ {
+			if rank, ok := self.ranks[parts[i]+parts[i+1]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return parts
+}
+
+// 將規則中的 \s 擴充為 Unicode 空白字元
+func toUnicodeSpaces(pattern string) string {
+	builder := strings.Builder{}
+	inClass := false
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			if pattern[i+1] == 's' {
+				if inClass {
+					builder.WriteString(unicodeSpaces)
+				} else {
+					builder.WriteString("[" + unicodeSpaces + "]")
+				}
+			} else {
+				builder.WriteString(pattern[i : i+2])
+			}
+			i++
+			continue
+		case pattern[i] == '[':
+			inClass = true
+		case pattern[i] == ']':
+			inClass = false
+		}
+		builder.WriteByte(pattern[i])
+	}
+	return builder.String()
+}
+
+// 是否全為空白字元
+func isSpaces(text string) bool {
+	for _, r := range text {
+		if !unicode.IsSpace(r) && !unicode.Is(unicode.Z, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// 未註冊詞彙表時使用的分詞規則, 用於估算 token 數量
+var estimatePattern = regexp.MustCompile(`\A(?:` + toUnicodeSpaces(encodingPatterns[Encoding_CL100K]) + `)`)
+
+// 未註冊詞彙表時估算 token 數量
+//
+// 英數片段多為 1 個 token, 較長的片段每 6 個字元增加 1 個 token, 其他文字約每個字 1 個 token
+func estimateTokens(text string) int {
+	count := 0
+	for _, piece := range splitPieces(estimatePattern, text) {
+		ascii := true
+		for i := 0; i < len(piece); i++ {
+			if piece[i] >= utf8.RuneSelf {
+				ascii = false
+				break
+			}
+		}
+
+		if ascii {
+			count += 1 + (len(piece)-1)/6
+		} else {
+			count += max(1, utf8.RuneCountInString(strings.TrimSpace(piece)))
+		}
+	}
+	return count
+}
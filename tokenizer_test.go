@@ -0,0 +1,64 @@
+package gptapi
+
+import (
+	"reflect"
+	"testing"
+)
+
+// 預期值取自 tiktoken 的輸出
+var encodeTests = []struct {
+	name     string
+	encoding string
+	text     string
+	tokens   []int
+}{
+	{"cl100k plain", Encoding_CL100K, "hello world", []int{15339, 1917}},
+	{"cl100k punctuation", Encoding_CL100K, "Hello, World! How's it going?", []int{9906, 11, 4435, 0, 2650, 596, 433, 2133, 30}},
+	{"cl100k cjk", Encoding_CL100K, "你好，世界！今天天氣很好。", []int{57668, 53901, 3922, 3574, 244, 98220, 6447, 37271, 36827, 36827, 30320, 96, 17599, 230, 53901, 1811}},
+	{"cl100k emoji", Encoding_CL100K, "emoji 😀🎉 test", []int{38623, 91416, 9468, 236, 231, 1296}},
+	{"cl100k spaces", Encoding_CL100K, "  a  b\n\nc", []int{220, 264, 220, 293, 271, 66}},
+	{"o200k plain", Encoding_O200K, "hello world", []int{24912, 2375}},
+	{"o200k punctuation", Encoding_O200K, "Hello, World! How's it going?", []int{13225, 11, 5922, 0, 3253, 885, 480, 2966, 30}},
+	{"o200k cjk", Encoding_O200K, "你好，世界！今天天氣很好。", []int{177519, 979, 28428, 3393, 10941, 1487, 74765, 148483, 788}},
+	{"o200k emoji", Encoding_O200K, "emoji 😀🎉 test", []int{75339, 88038, 71344, 231, 1746}},
+	{"o200k spaces", Encoding_O200K, "  a  b\n\nc", []int{220, 261, 220, 287, 279, 66}},
+}
+
+func TestEncode(t *testing.T) {
+	for _, tt := range encodeTests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoding, ok := GetEncoding(tt.encoding)
+			if !ok {
+				t.Fatalf("GetEncoding(%s) not registered", tt.encoding)
+			}
+
+			if got := encoding.Encode(tt.text); !reflect.DeepEqual(got, tt.tokens) {
+				t.Errorf("Encode = %v, want %v", got, tt.tokens)
+			}
+			if got := encoding.Count(tt.text); got != len(tt.tokens) {
+				t.Errorf("Count = %d, want %d", got, len(tt.tokens))
+			}
+			text, err := encoding.Decode(tt.tokens)
+			if err != nil || text != tt.text {
+				t.Errorf("Decode = %q, %v, want %q", text, err, tt.text)
+			}
+		})
+	}
+}
+
+func TestEncodingNameForModel(t *testing.T) {
+	tests := map[string]string{
+		"gpt-4o":        Encoding_O200K,
+		"gpt-4o-mini":   Encoding_O200K,
+		"gpt-4.1":       Encoding_O200K,
+		"o3-mini":       Encoding_O200K,
+		"gpt-5":         Encoding_O200K,
+		"gpt-4":         Encoding_CL100K,
+		"gpt-3.5-turbo": Encoding_CL100K,
+	}
+	for model, want := range tests {
+		if got := EncodingNameForModel(model); got != want {
+			t.Errorf("EncodingNameForModel(%s) = %s, want %s", model, got, want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package gptapi
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+)
+
+// 訊息格式額外消耗的 token 數量
+const (
+	tokensPerMessage = 3 // 每則訊息的格式 token
+	tokensPerName    = 1 // 訊息有 name 時額外的 token
+	tokensPerReply   = 3 // 模型回應前置的 token
+)
+
+// 圖片 token 計算規則
+const (
+	imageBaseTokens = 85   // 每張圖片的基本消耗, 低解析度只消耗此數量
+	imageTileTokens = 170  // 高解析度每個 512x512 區塊的消耗
+	imageTileSize   = 512  // 高解析度區塊大小
+	imageMaxSide    = 2048 // 高解析度先縮放至此範圍內
+	imageShortSide  = 768  // 高解析度再將短邊縮放至此長度
+)
+
+// 計算請求送出的 token 數量
+//
+// 依 OpenAI 的訊息格式規則計算, 並加上工具定義與模型回應前置的 token
+// 文字以內建的 cl100k_base 或 o200k_base 詞彙表計算, 詞彙表不存在時回傳 ErrEncodingNotRegistered
+// 圖片依 ImageTokens 規則計算, 無法取得尺寸的網址圖片以高解析度上限估算
+func CountTokens(model string, messages []IMessage, tools []Tool) (int, error) {
+	counter, exact := textCounterFor(model)
+	if !exact {
+		return 0, fmt.Errorf("[CountTokens] Error %s: %w", EncodingNameForModel(model), ErrEncodingNotRegistered)
+	}
+	return countTokens(model, counter, messages, tools)
+}
+
+// 計算請求送出的 token 數量, 未註冊詞彙表時以估算值代替
+//
+// @exact 為 true 表示以詞彙表精確計算, false 表示文字部分為估算值
+func EstimateTokens(model string, messages []IMessage, tools []Tool) (count int, exact bool, err error) {
+	counter, exact := textCounterFor(model)
+	count, err = countTokens(model, counter, messages, tools)
+	return count, exact, err
+}
+
+func countTokens(model string, counter func(text string) int, messages []IMessage, tools []Tool) (int, error) {
+	total := 0
+	for i, message := range messages {
+		count, err := countMessageTokens(counter, message)
+		if err != nil {
+			return 0, fmt.Errorf("[CountTokens] Error messages[%d]: %v", i, err)
+		}
+		total += count
+	}
+	total += countToolsTokens(model, counter, tools)
+
+	return total + tokensPerReply, nil
+}
+
+// 建立計算單一訊息 token 數量的 TokenCounter, 可用於 TokenBudgetStrategy
+//
+// 未註冊 model 對應的詞彙表時文字部分以估算值代替
+func NewMessageTokenCounter(model string) TokenCounter {
+	counter, _ := textCounterFor(model)
+	return func(message IMessage) int {
+		count, err := countMessageTokens(counter, message)
+		if err != nil {
+			return estimateMessageTokens(message)
+		}
+		return count
+	}
+}
+
+// 計算圖片消耗的 token 數量
+//
+// 低解析度固定 85, 高解析度先縮放至 2048x2048 內再將短邊縮放至 768,
+// 每個 512x512 區塊 170 並加上基本的 85, "auto" 或未指定時視為高解析度
+func ImageTokens(width, height int, detail string) int {
+	if detail == ImageDetailMode_Low {
+		return imageBaseTokens
+	}
+	if width <= 0 || height <= 0 {
+		return imageBaseTokens
+	}
+
+	w, h := float64(width), float64(height)
+	if w > imageMaxSide || h > imageMaxSide {
+		scale := imageMaxSide / max(w, h)
+		w, h = w*scale, h*scale
+	}
+	if min(w, h) > imageShortSide {
+		scale := imageShortSide / min(w, h)
+		w, h = w*scale, h*scale
+	}
+
+	tiles := ceilDiv(int(w+0.5), imageTileSize) * ceilDiv(int(h+0.5), imageTileSize)
+	return imageBaseTokens + imageTileTokens*tiles
+}
+
+func ceilDiv(a, b int) int {
+	return (a + b - 1) / b
+}
+
+// 取得模型對應的文字 token 計算方式, 未註冊詞彙表時使用估算
+//
+// @exact 是否以詞彙表精確計算
+func textCounterFor(model string) (counter func(text string) int, exact bool) {
+	if encoding, ok := GetEncoding(EncodingNameForModel(model)); ok {
+		return encoding.Count, true
+	}
+	return estimateTokens, false
+}
+
+// 計算單一訊息的 token 數量
+func countMessageTokens(counter func(text string) int, message IMessage) (int, error) {
+	js, err := json.Marshal(message)
+	if err != nil {
+		return 0, err
+	}
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(js, &fields); err != nil {
+		return 0, err
+	}
+
+	total := tokensPerMessage
+	for key, value := range fields {
+		switch key {
+		case "content":
+			content, err := decodeContent(value)
+			if err != nil {
+				return 0, err
+			}
+			total += countContentTokens(counter, content)
+		case "tool_calls":
+			var calls []ToolCalls
+			if err := json.Unmarshal(value, &calls); err != nil {
+				return 0, err
+			}
+			for _, call := range calls {
+				total += counter(call.Function.Name) + counter(call.Function.Arguments)
+			}
+		default:
+			var text string
+			if json.Unmarshal(value, &text) != nil {
+				continue
+			}
+			total += counter(text)
+			if key == "name" {
+				total += tokensPerName
+			}
+		}
+	}
+	return total, nil
+}
+
+// 計算訊息內文的 token 數量
+func countContentTokens(counter func(text string) int, content IContent) int {
+	switch c := content.(type) {
+	case string:
+		return counter(c)
+	case []ContentPart:
+		total := 0
+		for _, part := range c {
+			total += counter(part.Text)
+			if part.ImageURL != nil {
+				width, height := imageURLSize(part.ImageURL.URL)
+				total += ImageTokens(width, height, part.ImageURL.Detail)
+			}
+		}
+		return total
+	}
+	return 0
+}
+
+// 取得 base64 編碼圖片的尺寸, 網址圖片無法取得時以高解析度上限尺寸代替
+func imageURLSize(url string) (int, int) {
+	if strings.HasPrefix(url, "data:") {
+		if _, data, ok := strings.Cut(url, ";base64,"); ok {
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err == nil {
+				if config, _, err := image.DecodeConfig(bytes.NewReader(decoded)); err == nil {
+					return config.Width, config.Height
+				}
+			}
+		}
+	}
+	return imageShortSide, imageMaxSide
+}
+
+// 計算工具定義的 token 數量
+//
+// 工具定義的實際格式未公開, 依 OpenAI cookbook 的近似規則計算
+func countToolsTokens(model string, counter func(text string) int, tools []Tool) int {
+	if len(tools) == 0 {
+		return 0
+	}
+
+	funcInit, propInit, propKey, enumInit, enumItem, funcEnd := 7, 3, 3, -3, 3, 12
+	if EncodingNameForModel(model) == Encoding_CL100K {
+		funcInit = 10
+	}
+
+	total := 0
+	for _, tool := range tools {
+		function := tool.ToolFunction
+		total += funcInit
+		total += counter(function.Name + ":" + strings.TrimSuffix(function.Description, "."))
+
+		if len(function.Parameters.Properties) == 0 {
+			continue
+		}
+		total += propInit
+		for name, property := range function.Parameters.Properties {
+			total += propKey
+			if len(property.Enum) > 0 {
+				total += enumInit
+				for _, item := range property.Enum {
+					total += enumItem + counter(fmt.Sprint(item))
+				}
+			}
+			total += counter(name + ":" + property.Type + ":" + strings.TrimSuffix(property.Description, "."))
+		}
+	}
+	return total + funcEnd
+}
@@ -0,0 +1,70 @@
+package gptapi
+
+import "testing"
+
+// 預期值依 OpenAI cookbook 的計算規則與 tiktoken 的文字 token 數量
+func TestCountTokens(t *testing.T) {
+	weather := NewTool("get_weather", "Get the current weather.", FunctionParameters{
+		Type: "object",
+		Properties: map[string]Schema{
+			"location": {Type: "string", Description: "City name."},
+			"unit":     {Type: "string", Description: "Temperature unit", Enum: []string{"celsius", "fahrenheit"}},
+		},
+		Required: []string{"location", "unit"},
+	})
+	named := &UserMessage{Name: "bob", Role: MessageContentRole_User, Content: "What is the weather in Taipei?"}
+
+	tests := []struct {
+		name     string
+		model    string
+		messages []IMessage
+		tools    []Tool
+		want     int
+	}{
+		// 每則訊息 3 + role + content, 最後加上回應前置 3
+		{"single message", "gpt-4o", []IMessage{NewUserTextMessage("hello world")}, nil, 3 + 1 + 2 + 3},
+		{"system and user cl100k", "gpt-4", []IMessage{NewSystemTextMessage("You are a helpful assistant."), NewUserTextMessage("What is the weather in Taipei?")}, nil, (3 + 1 + 6) + (3 + 1 + 7) + 3},
+		{"system and user o200k", "gpt-4o", []IMessage{NewSystemTextMessage("You are a helpful assistant."), NewUserTextMessage("What is the weather in Taipei?")}, nil, (3 + 1 + 6) + (3 + 1 + 7) + 3},
+		// 有 name 時加上 name 的 token 與額外的 1
+		{"name", "gpt-4o", []IMessage{named}, nil, 3 + 1 + 7 + 1 + 1 + 3},
+		{"cjk", "gpt-4o", []IMessage{NewUserTextMessage("你好，世界！今天天氣很好。")}, nil, 3 + 1 + 9 + 3},
+		// 工具: 函數 7 (cl100k 為 10) + 名稱描述, 參數 3, 每個參數 3 + 內容, enum -3 + 每項 3 + 內容, 結尾 12
+		{"tools o200k", "gpt-4o", []IMessage{NewUserTextMessage("What is the weather in Taipei?")}, []Tool{weather}, (3 + 1 + 7 + 3) + (7 + 6) + 3 + (3 + 5) + (3 - 3 + (3 + 2) + (3 + 2) + 5) + 12},
+		{"tools cl100k", "gpt-4", []IMessage{NewUserTextMessage("What is the weather in Taipei?")}, []Tool{weather}, (3 + 1 + 7 + 3) + (10 + 6) + 3 + (3 + 5) + (3 - 3 + (3 + 2) + (3 + 2) + 5) + 12},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CountTokens(tt.model, tt.messages, tt.tools)
+			if err != nil {
+				t.Fatalf("CountTokens: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("CountTokens = %d, want %d", got, tt.want)
+			}
+
+			estimated, exact, err := EstimateTokens(tt.model, tt.messages, tt.tools)
+			if err != nil || !exact || estimated != tt.want {
+				t.Errorf("EstimateTokens = %d, %v, %v, want %d, true, nil", estimated, exact, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestImageTokens(t *testing.T) {
+	tests := []struct {
+		width, height int
+		detail        string
+		want          int
+	}{
+		{1024, 1024, ImageDetailMode_Low, 85},
+		{1024, 1024, ImageDetailMode_High, 765},
+		{2048, 4096, ImageDetailMode_High, 1105},
+		{512, 512, "", 255},
+	}
+	for _, tt := range tests {
+		if got := ImageTokens(tt.width, tt.height, tt.detail); got != tt.want {
+			t.Errorf("ImageTokens(%d, %d, %q) = %d, want %d", tt.width, tt.height, tt.detail, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package gptapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// 參數驗證發現的單一問題
+type ArgumentIssue struct {
+	Path    string `json:"path"`    // 問題位置 EX: "$.items[0].city"
+	Message string `json:"message"` // 問題說明
+}
+
+// 工具參數驗證失敗
+//
+// 可透過 ToolMessage 轉為回傳給模型的訊息, 讓模型修正參數後重新呼叫
+type ToolArgumentError struct {
+	Name   string          // 工具名稱
+	Issues []ArgumentIssue // 所有發現的問題
+}
+
+func (self *ToolArgumentError) Error() string {
+	issues := make([]string, 0, len(self.Issues))
+	for _, issue := range self.Issues {
+		issues = append(issues, fmt.Sprintf("%s: %s", issue.Path, issue.Message))
+	}
+	return fmt.Sprintf("invalid arguments for tool %s: %s", self.Name, strings.Join(issues, "; "))
+}
+
+// 轉為回傳給模型的 ToolMessage
+func (self *ToolArgumentError) ToolMessage(toolCallId string) IMessage {
+	buf := &bytes.Buffer{}
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(map[string]interface{}{
+		"error":  fmt.Sprintf("invalid arguments for tool %s, fix the arguments and call again", self.Name),
+		"issues": self.Issues,
+	})
+	return &ToolMessage{
+		Role:       MessageContentRole_Tool,
+		Content:    strings.TrimSpace(buf.String()),
+		ToolCallId: toolCallId,
+	}
+}
+
+// 檢查工具呼叫參數是否符合工具定義的 FunctionParameters
+//
+// 驗證 json 格式, 資料類型, 必要欄位, enum, 數值與陣列範圍及未定義欄位
+// 驗證失敗時回傳 *ToolArgumentError
+func ValidateArguments(tool Tool, arguments string) error {
+	validator := &argumentValidator{root: &tool.ToolFunction.Parameters}
+
+	decoder := json.NewDecoder(strings.NewReader(arguments))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		validator.addf("$", "invalid json: %v", err)
+	} else if decoder.More() {
+		validator.addf("$", "invalid json: unexpected data after top-level value")
+	} else {
+		validator.validate("$", validator.root, value)
+	}
+
+	if len(validator.issues) > 0 {
+		return &ToolArgumentError{Name: tool.ToolFunction.Name, Issues: validator.issues}
+	}
+	return nil
+}
+
+// 參數驗證狀態
+type argumentValidator struct {
+	root   *Schema
+	issues []ArgumentIssue
+}
+
+func (self *argumentValidator) addf(path, format string, args ...interface{}) {
+	self.issues = append(self.issues, ArgumentIssue{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// 取得 $ref 對應的定義
+func (self *argumentValidator) resolve(ref string) (*Schema, bool) {
+	if ref == "#" {
+		return self.root, true
+	}
+
+	name, ok := strings.CutPrefix(ref, "#/$defs/")
+	if !ok {
+		return nil, false
+	}
+	def, ok := self.root.Defs[name]
+	return &def, ok
+}
+
+// 遞迴驗證值
+func (self *argumentValidator) validate(path string, schema *Schema, value interface{}) {
+	if schema.Ref != "" {
+		resolved, ok := self.resolve(schema.Ref)
+		if !ok {
+			self.addf(path, "unresolved schema reference %q", schema.Ref)
+			return
+		}
+		schema = resolved
+	}
+
+	if len(schema.AnyOf) > 0 {
+		for i := range schema.AnyOf {
+			branch := &argumentValidator{root: self.root}
+			branch.validate(path, &schema.AnyOf[i], value)
+			if len(branch.issues) == 0 {
+				return
+			}
+		}
+		self.addf(path, "value does not match any allowed schema")
+		return
+	}
+
+	if !self.checkType(path, schema.Type, value) {
+		return
+	}
+
+	if enum := schema.EnumValues(); len(enum) > 0 && !slices.ContainsFunc(enum, func(allowed interface{}) bool {
+		return jsonValueEqual(allowed, value)
+	}) {
+		self.addf(path, "value must be one of %s", jsonString(enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		self.validateObject(path, schema, v)
+	case []interface{}:
+		self.validateArray(path, schema, v)
+	case json.Number:
+		number, _ := v.Float64()
+		if schema.Minimum != nil && number < *schema.Minimum {
+			self.addf(path, "value must be >= %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && number > *schema.Maximum {
+			self.addf(path, "value must be <= %v", *schema.Maximum)
+		}
+	case string:
+		if schema.Pattern != "" {
+			if pattern, err := regexp.Compile(schema.Pattern); err == nil && !pattern.MatchString(v) {
+				self.addf(path, "value must match pattern %q", schema.Pattern)
+			}
+		}
+	}
+}
+
+// 檢查資料類型, 類型不符時回傳 false
+func (self *argumentValidator) checkType(path, schemaType string, value interface{}) bool {
+	ok := true
+	switch schemaType {
+	case "":
+		return true
+	case "object":
+		_, ok = value.(map[string]interface{})
+	case "array":
+		_, ok = value.([]interface{})
+	case "string":
+		_, ok = value.(string)
+	case "boolean":
+		_, ok = value.(bool)
+	case "null":
+		ok = value == nil
+	case "number":
+		_, ok = value.(json.Number)
+	case "integer":
+		var number json.Number
+		if number, ok = value.(json.Number); ok {
+			f, err := number.Float64()
+			ok = err == nil && f == math.Trunc(f)
+		}
+	}
+
+	if !ok {
+		self.addf(path, "expected %s, got %s", schemaType, jsonTypeName(value))
+	}
+	return ok
+}
+
+// 驗證物件欄位
+func (self *argumentValidator) validateObject(path string, schema *Schema, value map[string]interface{}) {
+	for _, name := range schema.Required {
+		if _, ok := value[name]; !ok {
+			self.addf(path, "missing required property %q", name)
+		}
+	}
+
+	names := make([]string, 0, len(value))
+	for name := range value {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		property, ok := schema.Properties[name]
+		if !ok {
+			if !schema.AdditionalProperties {
+				self.addf(path, "unknown property %q", name)
+			}
+			continue
+		}
+		self.validate(path+"."+name, &property, value[name])
+	}
+}
+
+// 驗證陣列元素
+func (self *argumentValidator) validateArray(path string, schema *Schema, value []interface{}) {
+	if schema.MinItems != nil && len(value) < *schema.MinItems {
+		self.addf(path, "array must have at least %d items", *schema.MinItems)
+	}
+	if schema.MaxItems != nil && len(value) > *schema.MaxItems {
+		self.addf(path, "array must have at most %d items", *schema.MaxItems)
+	}
+
+	if schema.Items == nil {
+		return
+	}
+	for i, item := range value {
+		self.validate(fmt.Sprintf("%s[%d]", path, i), schema.Items, item)
+	}
+}
+
+// json 值的類型名稱
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// 以 json 編碼比較兩個值是否相同, 數值以數值大小比較
+func jsonValueEqual(a, b interface{}) bool {
+	if number, ok := b.(json.Number); ok {
+		f, err := number.Float64()
+		if err != nil {
+			return false
+		}
+		switch v := a.(type) {
+		case int:
+			return float64(v) == f
+		case int64:
+			return float64(v) == f
+		case float64:
+			return v == f
+		case json.Number:
+			other, err := v.Float64()
+			return err == nil && other == f
+		}
+		return false
+	}
+
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// 以 json 格式輸出值, 用於錯誤訊息
+func jsonString(value interface{}) string {
+	js, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(js)
+}
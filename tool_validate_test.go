@@ -0,0 +1,117 @@
+package gptapi
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestValidateArguments(t *testing.T) {
+	minDays, maxDays := 1.0, 7.0
+	minTags, maxTags := 1, 2
+	params := FunctionParameters{
+		Type: "object",
+		Properties: map[string]Schema{
+			"city":  {Type: "string", Pattern: "^[A-Z]"},
+			"unit":  {Type: "string", Enum: []string{"celsius", "fahrenheit"}},
+			"days":  {Type: "integer", Minimum: &minDays, Maximum: &maxDays},
+			"scale": {Type: "number", Enum: []string{"0.5", "1"}},
+			"tags":  {Type: "array", Items: &Schema{Type: "string"}, MinItems: &minTags, MaxItems: &maxTags},
+			"note":  {AnyOf: []Schema{{Type: "string"}, {Type: "null"}}},
+			"list":  {Ref: "#/$defs/Item"},
+		},
+		Required: []string{"city", "days"},
+		Defs: map[string]Schema{
+			"Item": {
+				Type:       "object",
+				Properties: map[string]Schema{"name": {Type: "string"}, "next": {AnyOf: []Schema{{Ref: "#/$defs/Item"}, {Type: "null"}}}},
+				Required:   []string{"name", "next"},
+			},
+		},
+	}
+	tool := NewTool("get_weather", "Get the weather.", params)
+
+	tests := []struct {
+		name      string
+		arguments string
+		issues    []ArgumentIssue
+	}{
+		{"valid", `{"city":"Taipei","days":3,"unit":"celsius","scale":1.0,"tags":["a"],"note":null,"list":{"name":"a","next":{"name":"b","next":null}}}`, nil},
+		{"invalid json", `{"city":`, []ArgumentIssue{{"$", "invalid json: unexpected EOF"}}},
+		{"trailing data", `{"city":"Taipei","days":1} {}`, []ArgumentIssue{{"$", "invalid json: unexpected data after top-level value"}}},
+		{"root not object", `[]`, []ArgumentIssue{{"$", "expected object, got array"}}},
+		{"missing required", `{}`, []ArgumentIssue{{"$", `missing required property "city"`}, {"$", `missing required property "days"`}}},
+		{"unknown property", `{"city":"Taipei","days":1,"extra":true}`, []ArgumentIssue{{"$", `unknown property "extra"`}}},
+		{"wrong type", `{"city":1,"days":"3"}`, []ArgumentIssue{{"$.city", "expected string, got number"}, {"$.days", "expected integer, got string"}}},
+		{"not integer", `{"city":"Taipei","days":1.5}`, []ArgumentIssue{{"$.days", "expected integer, got number"}}},
+		{"out of range", `{"city":"Taipei","days":8}`, []ArgumentIssue{{"$.days", "value must be <= 7"}}},
+		{"below range", `{"city":"Taipei","days":0}`, []ArgumentIssue{{"$.days", "value must be >= 1"}}},
+		{"enum", `{"city":"Taipei","days":1,"unit":"kelvin"}`, []ArgumentIssue{{"$.unit", `value must be one of ["celsius","fahrenheit"]`}}},
+		{"number enum", `{"city":"Taipei","days":1,"scale":2}`, []ArgumentIssue{{"$.scale", "value must be one of [0.5,1]"}}},
+		{"pattern", `{"city":"taipei","days":1}`, []ArgumentIssue{{"$.city", `value must match pattern "^[A-Z]"`}}},
+		{"too few items", `{"city":"Taipei","days":1,"tags":[]}`, []ArgumentIssue{{"$.tags", "array must have at least 1 items"}}},
+		{"too many items", `{"city":"Taipei","days":1,"tags":["a","b","c"]}`, []ArgumentIssue{{"$.tags", "array must have at most 2 items"}}},
+		{"item type", `{"city":"Taipei","days":1,"tags":[1]}`, []ArgumentIssue{{"$.tags[0]", "expected string, got number"}}},
+		{"anyOf", `{"city":"Taipei","days":1,"note":1}`, []ArgumentIssue{{"$.note", "value does not match any allowed schema"}}},
+		{"ref", `{"city":"Taipei","days":1,"list":{"name":"a"}}`, []ArgumentIssue{{"$.list", `missing required property "next"`}}},
+		{"nested ref", `{"city":"Taipei","days":1,"list":{"name":"a","next":{"name":2,"next":null}}}`, []ArgumentIssue{{"$.list.next", "value does not match any allowed schema"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateArguments(tool, tt.arguments)
+			if tt.issues == nil {
+				if err != nil {
+					t.Errorf("ValidateArguments = %v, want nil", err)
+				}
+				return
+			}
+
+			var argErr *ToolArgumentError
+			if !errors.As(err, &argErr) {
+				t.Fatalf("ValidateArguments = %v, want *ToolArgumentError", err)
+			}
+			if argErr.Name != "get_weather" {
+				t.Errorf("Name = %q, want get_weather", argErr.Name)
+			}
+			if !reflect.DeepEqual(argErr.Issues, tt.issues) {
+				t.Errorf("Issues = %q, want %q", argErr.Issues, tt.issues)
+			}
+		})
+	}
+}
+
+func TestValidateArgumentsUnresolvedRef(t *testing.T) {
+	tool := NewTool("t", "", FunctionParameters{
+		Type:       "object",
+		Properties: map[string]Schema{"a": {Ref: "#/$defs/Missing"}},
+	})
+	err := ValidateArguments(tool, `{"a":1}`)
+	var argErr *ToolArgumentError
+	if !errors.As(err, &argErr) || argErr.Issues[0].Message != `unresolved schema reference "#/$defs/Missing"` {
+		t.Errorf("ValidateArguments = %v, want unresolved reference", err)
+	}
+}
+
+func TestToolArgumentErrorToolMessage(t *testing.T) {
+	err := &ToolArgumentError{Name: "t", Issues: []ArgumentIssue{{Path: "$.a", Message: "expected string, got number"}}}
+	message, ok := err.ToolMessage("call_1").(*ToolMessage)
+	if !ok {
+		t.Fatalf("ToolMessage type = %T, want *ToolMessage", err.ToolMessage("call_1"))
+	}
+	if message.ToolCallId != "call_1" || message.Role != MessageContentRole_Tool {
+		t.Errorf("ToolMessage = %+v", message)
+	}
+
+	content := struct {
+		Error  string          `json:"error"`
+		Issues []ArgumentIssue `json:"issues"`
+	}{}
+	if err := json.Unmarshal([]byte(message.Content.(string)), &content); err != nil {
+		t.Fatalf("content: %v", err)
+	}
+	if content.Error == "" || !reflect.DeepEqual(content.Issues, err.Issues) {
+		t.Errorf("content = %+v", content)
+	}
+}
@@ -0,0 +1,119 @@
+package gptapi
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+var toolChoiceWireTests = []struct {
+	name   string
+	choice IToolChoice
+	wire   string
+}{
+	{"none", NewToolChoiceNone(), `"none"`},
+	{"auto", NewToolChoiceAuto(), `"auto"`},
+	{"required", NewToolChoiceRequired(), `"required"`},
+	{"function", NewToolChoiceFunction("x"), `{"type":"function","function":{"name":"x"}}`},
+}
+
+func TestToolChoiceMarshal(t *testing.T) {
+	for _, tt := range toolChoiceWireTests {
+		t.Run(tt.name, func(t *testing.T) {
+			js, err := json.Marshal(tt.choice)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(js) != tt.wire {
+				t.Errorf("Marshal = %s, want %s", js, tt.wire)
+			}
+			if got := tt.choice.Contents(); got != tt.wire && `"`+got+`"` != tt.wire {
+				t.Errorf("Contents = %s, want %s", got, tt.wire)
+			}
+		})
+	}
+}
+
+func TestParseToolChoice(t *testing.T) {
+	for _, tt := range toolChoiceWireTests {
+		t.Run(tt.name, func(t *testing.T) {
+			choice, err := ParseToolChoice([]byte(tt.wire))
+			if err != nil {
+				t.Fatalf("ParseToolChoice: %v", err)
+			}
+			if !reflect.DeepEqual(choice, tt.choice) {
+				t.Errorf("ParseToolChoice = %#v, want %#v", choice, tt.choice)
+			}
+		})
+	}
+
+	choice, err := ParseToolChoice([]byte("null"))
+	if err != nil || choice != nil {
+		t.Errorf("ParseToolChoice(null) = %#v, %v, want nil, nil", choice, err)
+	}
+	if _, err := ParseToolChoice([]byte("[1]")); err == nil {
+		t.Error("ParseToolChoice([1]) error = nil, want error")
+	}
+}
+
+func TestChatCompletionRequestToolChoiceRoundTrip(t *testing.T) {
+	for _, tt := range toolChoiceWireTests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewChatCompletionRequest(DefaultModel).
+				WithUser("hi").
+				WithTools(NewTool("x", "test", NewToolFunctionParameters(nil))).
+				WithToolChoice(tt.choice)
+
+			js, err := json.Marshal(req)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			fields := map[string]json.RawMessage{}
+			if err := json.Unmarshal(js, &fields); err != nil {
+				t.Fatalf("Unmarshal fields: %v", err)
+			}
+			if got := string(fields["tool_choice"]); got != tt.wire {
+				t.Errorf("tool_choice = %s, want %s", got, tt.wire)
+			}
+
+			var decoded ChatCompletionRequest
+			if err := json.Unmarshal(js, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(decoded.ToolChoice, tt.choice) {
+				t.Errorf("ToolChoice = %#v, want %#v", decoded.ToolChoice, tt.choice)
+			}
+			if err := decoded.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateToolChoiceForms(t *testing.T) {
+	auto := ToolChoiceString(ToolChoice_Auto)
+	tests := []struct {
+		name    string
+		choice  IToolChoice
+		wantErr bool
+	}{
+		{"string", ToolChoiceString(ToolChoice_Required), false},
+		{"string pointer", &auto, false},
+		{"object", ToolChoiceObject{Type: ToolType_Function, ToolChoiceFunction: ToolChoiceFunction{Name: "x"}}, false},
+		{"object pointer", NewToolChoiceFunction("x"), false},
+		{"invalid string", ToolChoiceString("always"), true},
+		{"unknown function", NewToolChoiceFunction("y"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := NewChatCompletionRequest(DefaultModel).
+				WithUser("hi").
+				WithTools(NewTool("x", "test", NewToolFunctionParameters(nil))).
+				WithToolChoice(tt.choice)
+			if err := req.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package gptapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 工具處理函式
+//
+// @arguments 模型提供的參數 json string
+// 回傳值為 string 時直接作為結果內文, 其他型別以 json 編碼
+type ToolHandler func(ctx context.Context, arguments string) (interface{}, error)
+
+// 已註冊的工具
+type registeredTool struct {
+	tool    Tool
+	handler ToolHandler
+}
+
+// 工具註冊表, 依名稱對應工具定義與處理函式
+type ToolRegistry struct {
+	mu    sync.RWMutex
+	tools map[string]registeredTool
+	order []string // 註冊順序, 讓 Tools 輸出穩定
+}
+
+func NewToolRegistry() *ToolRegistry {
+	return &ToolRegistry{
+		tools: make(map[string]registeredTool),
+	}
+}
+
+// 註冊工具, 名稱重複時回傳錯誤
+func (self *ToolRegistry) Register(tool Tool, handler ToolHandler) error {
+	name := tool.ToolFunction.Name
+	if !toolNamePattern.MatchString(name) {
+		return fmt.Errorf("[ToolRegistry] Error invalid function name: %q", name)
+	}
+	if handler == nil {
+		return fmt.Errorf("[ToolRegistry] Error nil handler: %s", name)
+	}
+
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	if _, exists := self.tools[name]; exists {
+		return fmt.Errorf("[ToolRegistry] Error duplicate function name: %s", name)
+	}
+	self.tools[name] = registeredTool{tool: tool, handler: handler}
+	self.order = append(self.order, name)
+	return nil
+}
+
+// 以 Go struct 定義參數註冊工具, 參數定義由 NewToolFor 以 strict 模式產生
+//
+//	gptapi.RegisterTool(registry, "get_weather", "查詢天氣",
+//		func(ctx context.Context, args WeatherArgs) (interface{}, error) {
+//			return weather.Get(ctx, args.City)
+//		})
+func RegisterTool[T any](registry *ToolRegistry, name, description string, handler func(ctx context.Context, args T) (interface{}, error)) error {
+	var zero T
+	tool, err := NewToolFor(name, description, &zero, true)
+	if err != nil {
+		return err
+	}
+
+	return registry.Register(tool, func(ctx context.Context, arguments string) (interface{}, error) {
+		args := new(T)
+		if err := json.Unmarshal([]byte(arguments), args); err != nil {
+			return nil, fmt.Errorf("[%s] Error invalid arguments: %v", name, err)
+		}
+		return handler(ctx, *args)
+	})
+}
+
+// 已註冊的工具定義, 依註冊順序
+func (self *ToolRegistry) Tools() []Tool {
+	self.mu.RLock()
+	defer self.mu.RUnlock()
+
+	tools := make([]Tool, 0, len(self.order))
+	for _, name := range self.order {
+		tools = append(tools, self.tools[name].tool)
+	}
+	return tools
+}
+
+// 執行單一工具呼叫, 回傳可附加到對話中的 ToolMessage
+//
+// 呼叫未註冊的工具或參數不符合工具定義時不執行處理函式, 改回傳說明問題的 ToolMessage 讓模型修正後重新呼叫
+func (self *ToolRegistry) Call(ctx context.Context, call ToolCalls) (IMessage, error) {
+	self.mu.RLock()
+	registered, ok := self.tools[call.Function.Name]
+	available := append([]string(nil), self.order...)
+	self.mu.RUnlock()
+	if !ok {
+		return newToolResultMessage(call.ID, map[string]interface{}{
+			"error":           fmt.Sprintf("unknown tool %s, call one of the available tools", call.Function.Name),
+			"available_tools": available,
+		})
+	}
+
+	if err := ValidateArguments(registered.tool, call.Function.Arguments); err != nil {
+		var argErr *ToolArgumentError
+		if errors.As(err, &argErr) {
+			return argErr.ToolMessage(call.ID), nil
+		}
+		return nil, err
+	}
+
+	result, err := registered.handler(ctx, call.Function.Arguments)
+	if err != nil {
+		return nil, &ToolCallError{Name: call.Function.Name, CallID: call.ID, Err: err}
+	}
+
+	return newToolResultMessage(call.ID, result)
+}
+
+// 執行多個工具呼叫, 回傳的訊息順序與 calls 相同
+//
+// @parallel 是否同時執行, 依序執行時遇到錯誤即停止並回傳該錯誤, 同時執行時以 errors.Join 回傳所有錯誤
+func (self *ToolRegistry) CallAll(ctx context.Context, calls []ToolCalls, parallel bool) ([]IMessage, error) {
+	messages := make([]IMessage, len(calls))
+	if !parallel {
+		for i, call := range calls {
+			message, err := self.Call(ctx, call)
+			if err != nil {
+				return nil, err
+			}
+			messages[i] = message
+		}
+		return messages, nil
+	}
+
+	errs := make([]error, len(calls))
+	wg := sync.WaitGroup{}
+	for i, call := range calls {
+		wg.Add(1)
+		go func(i int, call ToolCalls) {
+			defer wg.Done()
+			messages[i], errs[i] = self.Call(ctx, call)
+		}(i, call)
+	}
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// 將工具結果包裝為 ToolMessage
+func newToolResultMessage(id string, result interface{}) (IMessage, error) {
+	if text, ok := result.(string); ok {
+		return &ToolMessage{
+			Role:       MessageContentRole_Tool,
+			Content:    text,
+			ToolCallId: id,
+		}, nil
+	}
+
+	js, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("[ToolRegistry] Error marshal result: %v", err)
+	}
+	return &ToolMessage{
+		Role:       MessageContentRole_Tool,
+		Content:    string(js),
+		ToolCallId: id,
+	}, nil
+}
+
+// RunConversation 執行設定
+type runConfig struct {
+	maxIterations int  // 最多呼叫模型次數
+	parallel      bool // 是否同時執行同一回應中的多個工具呼叫
+}
+
+// RunConversation 設定選項
+type RunOption func(*runConfig)
+
+// 設定最多呼叫模型次數, 預設 10
+func WithMaxIterations(maxIterations int) RunOption {
+	return func(c *runConfig) {
+		if maxIterations > 0 {
+			c.maxIterations = maxIterations
+		}
+	}
+}
+
+// 設定是否同時執行同一回應中的多個工具呼叫
+func WithParallelTools(parallel bool) RunOption {
+	return func(c *runConfig) {
+		c.parallel = parallel
+	}
+}
+
+// RunConversation 執行結果
+type RunResult struct {
+	Response   *CompletionsResponse // 最後一次模型回應
+	Messages   []IMessage           // 完整訊息紀錄, 含模型回應與工具結果
+	Usage      Usage                // 所有模型呼叫累計的 token 使用紀錄
+	Iterations int                  // 呼叫模型次數
+}
+
+// 自動執行工具呼叫的對話流程
+//
+// 呼叫模型後執行回應中的工具呼叫並將結果回傳給模型, 直到模型不再呼叫工具,
+// 達到最多呼叫次數 (回傳 ErrMaxIterations) 或工具處理函式回傳錯誤
+// req 未設定 Tools 時使用 registry 中所有工具
+// 發生錯誤時仍回傳目前為止的 RunResult
+func (self *Client) RunConversation(ctx context.Context, req ChatCompletionRequest, registry *ToolRegistry, opts ...RunOption) (*RunResult, error) {
+	if self == nil {
+		return &RunResult{Messages: req.Messages}, errors.New("[RunConversation] Error client is nil")
+	}
+	if registry == nil {
+		return &RunResult{Messages: req.Messages}, errors.New("[RunConversation] Error registry is nil")
+	}
+
+	config := &runConfig{maxIterations: 10}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	if len(req.Tools) == 0 {
+		req.Tools = registry.Tools()
+	}
+	req.Messages = append([]IMessage(nil), req.Messages...)
+
+	result := &RunResult{}
+	for result.Iterations < config.maxIterations {
+		response, err := self.CompletionsContext(ctx, req)
+		if err != nil {
+			result.Messages = req.Messages
+			return result, err
+		}
+		result.Iterations++
+		result.Response = response
+		result.Usage.Add(response.Usage)
+
+		if len(response.Choices) == 0 {
+			result.Messages = req.Messages
+			return result, errors.New("[RunConversation] Error response has no choices")
+		}
+
+		choice := response.Choices[0]
+		message := choice.Message
+		req.Messages = append(req.Messages, &message)
+
+		if len(message.ToolCalls) == 0 || choice.FinishReason == MessageFinishType_Stop {
+			result.Messages = req.Messages
+			return result, nil
+		}
+
+		toolMessages, err := registry.CallAll(ctx, message.ToolCalls, config.parallel)
+		if err != nil {
+			result.Messages = req.Messages
+			return result, err
+		}
+		req.Messages = append(req.Messages, toolMessages...)
+	}
+
+	result.Messages = req.Messages
+	return result, ErrMaxIterations
+}
@@ -0,0 +1,31 @@
+package gptapi
+
+import (
+	"context"
+	"testing"
+)
+
+func TestRunConversationNilArguments(t *testing.T) {
+	req := *NewChatCompletionRequest(DefaultModel).WithUser("hi")
+	var nilClient *Client
+
+	tests := []struct {
+		name     string
+		client   *Client
+		registry *ToolRegistry
+	}{
+		{"nil registry", NewClient(WithAPIKey("key")), nil},
+		{"nil client", nilClient, NewToolRegistry()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := tt.client.RunConversation(context.Background(), req, tt.registry)
+			if err == nil {
+				t.Fatal("error = nil, want error")
+			}
+			if result == nil || len(result.Messages) != 1 {
+				t.Errorf("result = %+v, want original messages", result)
+			}
+		})
+	}
+}
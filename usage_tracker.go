@@ -0,0 +1,157 @@
+package gptapi
+
+import (
+	"sync"
+)
+
+// 累計的使用紀錄
+type UsageSummary struct {
+	Requests int     // 請求數量
+	Usage    Usage   // 累計 token 使用紀錄
+	Cost     float64 // 累計費用, 單位為美金, 不含無法計價的請求
+	Unpriced int     // 模型沒有價格資料而無法計價的請求數量
+}
+
+func (self *UsageSummary) add(usage Usage, cost float64, priced bool) {
+	self.Requests++
+	self.Usage.Add(usage)
+	self.Cost += cost
+	if !priced {
+		self.Unpriced++
+	}
+}
+
+// 使用紀錄統計, 依模型與呼叫端提供的標籤累計 token 與費用
+//
+// 可同時由多個 goroutine 使用
+//
+//	tracker := gptapi.NewUsageTracker()
+//	response, err := client.Completions(req)
+//	tracker.AddResponse(response, "team:search", "feature:rerank")
+//	fmt.Println(tracker.ByTag()["team:search"].Cost)
+type UsageTracker struct {
+	mu      sync.Mutex
+	total   UsageSummary
+	byModel map[string]*UsageSummary
+	byTag   map[string]*UsageSummary
+}
+
+func NewUsageTracker() *UsageTracker {
+	return &UsageTracker{
+		byModel: make(map[string]*UsageSummary),
+		byTag:   make(map[string]*UsageSummary),
+	}
+}
+
+// 加入一般請求的使用紀錄
+func (self *UsageTracker) Add(model string, usage Usage, tags ...string) {
+	cost, err := Cost(usage, model)
+	self.add(model, usage, cost, err == nil, tags)
+}
+
+// 加入批次請求的使用紀錄, 費用套用 BatchDiscount
+func (self *UsageTracker) AddBatch(model string, usage Usage, tags ...string) {
+	cost, err := BatchCost(usage, model)
+	self.add(model, usage, cost, err == nil, tags)
+}
+
+// 加入模型回應的使用紀錄, 串流回應可先以 StreamAccumulator 組合
+func (self *UsageTracker) AddResponse(response *CompletionsResponse, tags ...string) {
+	if response == nil {
+		return
+	}
+	self.Add(response.Model, response.Usage, tags...)
+}
+
+// 加入文字向量回應的使用紀錄
+func (self *UsageTracker) AddEmbeddings(response *EmbeddingsResponse, tags ...string) {
+	if response == nil {
+		return
+	}
+	self.Add(response.Model, response.Usage, tags...)
+}
+
+// 加入串流片段的使用紀錄, 只有含 Usage 的最後片段會被計入
+func (self *UsageTracker) AddChunk(chunk ChatCompletionChunk, tags ...string) {
+	if chunk.Usage == nil {
+		return
+	}
+	self.Add(chunk.Model, *chunk.Usage, tags...)
+}
+
+// 加入批次結果中所有成功回應的使用紀錄
+func (self *UsageTracker) AddBatchOutput(output *RetrieveFileContentResponse, tags ...string) {
+	if output == nil {
+		return
+	}
+	for _, data := range output.Data {
+		switch response := data.Response.Body.(type) {
+		case *CompletionsResponse:
+			if response != nil {
+				self.AddBatch(response.Model, response.Usage, tags...)
+			}
+		case *EmbeddingsResponse:
+			if response != nil {
+				self.AddBatch(response.Model, response.Usage, tags...)
+			}
+		}
+	}
+}
+
+func (self *UsageTracker) add(model string, usage Usage, cost float64, priced bool, tags []string) {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+
+	self.total.add(usage, cost, priced)
+	summaryOf(self.byModel, model).add(usage, cost, priced)
+	for _, tag := range tags {
+		summaryOf(self.byTag, tag).add(usage, cost, priced)
+	}
+}
+
+func summaryOf(summaries map[string]*UsageSummary, key string) *UsageSummary {
+	summary, ok := summaries[key]
+	if !ok {
+		summary = &UsageSummary{}
+		summaries[key] = summary
+	}
+	return summary
+}
+
+// 所有請求的累計紀錄
+func (self *UsageTracker) Total() UsageSummary {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return self.total
+}
+
+// 依模型的累計紀錄
+func (self *UsageTracker) ByModel() map[string]UsageSummary {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return copySummaries(self.byModel)
+}
+
+// 依標籤的累計紀錄
+func (self *UsageTracker) ByTag() map[string]UsageSummary {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	return copySummaries(self.byTag)
+}
+
+// 清除所有累計紀錄
+func (self *UsageTracker) Reset() {
+	self.mu.Lock()
+	defer self.mu.Unlock()
+	self.total = UsageSummary{}
+	self.byModel = make(map[string]*UsageSummary)
+	self.byTag = make(map[string]*UsageSummary)
+}
+
+func copySummaries(summaries map[string]*UsageSummary) map[string]UsageSummary {
+	result := make(map[string]UsageSummary, len(summaries))
+	for key, summary := range summaries {
+		result[key] = *summary
+	}
+	return result
+}
@@ -2,43 +2,37 @@ package gptapi
 
 import (
 	"bufio"
-	"encoding/base64"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
-	"slices"
 )
 
+// 讀取圖片檔案並轉為 base64 編碼的 data URL, 不縮放圖片
+//
+// 需要縮放或由其他來源讀取時改用 EncodeImageFile, EncodeImageReader, EncodeImageBytes 或 EncodeImage
 func ImageEncode(imagepath string) (string, error) {
-	extName := filepath.Ext(imagepath)
-	if extName == "" {
-		return "", fmt.Errorf("[EncodeLocationImage] Error imagepath: %s", imagepath)
-	}
-
-	if !slices.Contains(SupImage, extName) {
-		return "", fmt.Errorf("[EncodeLocationImage] Error not support file type: %s", extName)
-	}
-
-	file, err := os.Open(imagepath)
-	if err != nil {
-		return "", fmt.Errorf("[EncodeLocationImage] Error open: %s ,err: %v", imagepath, err)
-	}
-	defer file.Close()
+	return EncodeImageFile(imagepath, ImageOptions{})
+}
 
-	data, err := io.ReadAll(file)
-	if err != nil {
-		return "", fmt.Errorf("[EncodeLocationImage] Error ReadAll: %s ,err: %v", imagepath, err)
+// 將批次指令寫入檔案內, 寫入前依模型調整請求內容 (參考 NormalizeForModel)
+func NewJsonlFile(dirPath, filename string, records []Record) error {
+	normalized := make([]Record, len(records))
+	for i, record := range records {
+		record.Body.NormalizeForModel()
+		normalized[i] = record
 	}
-
-	b64Str := base64.StdEncoding.EncodeToString(data)
-	return fmt.Sprintf("data:image/%s;base64,%s", extName[1:], b64Str), nil
+	return writeJsonlFile(dirPath, filename, normalized)
 }
 
-// 將批次指令寫入檔案內
-func NewJsonlFile(dirPath, filename string, records []Record) error {
+// 將文字向量批次指令寫入檔案內, 上傳後以 CreateEmbeddingsBatch 建立批次
+func NewEmbeddingsJsonlFile(dirPath, filename string, records []EmbeddingsRecord) error {
+	return writeJsonlFile(dirPath, filename, records)
+}
 
+// 將每筆資料以一行 json 寫入 dirPath/filename.jsonl
+func writeJsonlFile[T any](dirPath, filename string, records []T) error {
 	jsonlPath := fmt.Sprintf("%s/%s.jsonl", dirPath, filename)
 	// 创建文件
 	file, err := os.Create(jsonlPath)
@@ -69,6 +63,34 @@ func NewJsonlFile(dirPath, filename string, records []Record) error {
 	return nil
 }
 
+// 讀取 NewJsonlFile 寫入的批次指令檔案, 訊息依 role 還原為對應的訊息結構
+func ReadJsonlFile(jsonlPath string) ([]Record, error) {
+	file, err := os.Open(jsonlPath)
+	if err != nil {
+		return nil, fmt.Errorf("[ReadJsonlFile] Error open: %s ,err: %v", jsonlPath, err)
+	}
+	defer file.Close()
+
+	records := []Record{}
+	reader := bufio.NewReader(file)
+	for line := 1; ; line++ {
+		data, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(data)) > 0 {
+			record := Record{}
+			if err := json.Unmarshal(data, &record); err != nil {
+				return nil, fmt.Errorf("[ReadJsonlFile] Error line %d: %v", line, err)
+			}
+			records = append(records, record)
+		}
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("[ReadJsonlFile] Error read: %v", err)
+		}
+	}
+}
+
 // //// User Message 區塊
 func NewUserTextMessage(text string) IMessage {
 	return &UserMessage{
@@ -77,21 +99,28 @@ func NewUserTextMessage(text string) IMessage {
 	}
 }
 
+// 生成包含一張低解析度圖片的使用者訊息, 文字在前圖片在後, text 為空字串時只有圖片
+//
+// 多張圖片或需要指定解析度時改用 ContentBuilder
 func NewUserImageMessage(text, imageUrl string) IMessage {
-	return &UserMessage{
-		Role: MessageContentRole_User,
-		Content: []ContentImage{
-			{
-				Type: MessageContentType_Image,
-				ImageURL: &ContentImageData{
-					URL:    imageUrl,
-					Detail: ImageDetailMode_Low,
-				},
-			}, {
-				Type: MessageContentType_Text,
-				Text: text,
-			},
+	var parts []ContentPart
+	if text != "" {
+		parts = append(parts, ContentPart{
+			Type: MessageContentType_Text,
+			Text: text,
+		})
+	}
+	parts = append(parts, ContentPart{
+		Type: MessageContentType_Image,
+		ImageURL: &ContentImageData{
+			URL:    imageUrl,
+			Detail: ImageDetailMode_Low,
 		},
+	})
+
+	return &UserMessage{
+		Role:    MessageContentRole_User,
+		Content: parts,
 	}
 }
 
@@ -103,6 +132,14 @@ func NewSystemTextMessage(text string) IMessage {
 	}
 }
 
+// //// Developer Message 區塊
+func NewDeveloperTextMessage(text string) IMessage {
+	return &DeveloperMessage{
+		Role:    MessageContentRole_Developer,
+		Content: text,
+	}
+}
+
 // //// Assistant Message 區塊
 func NewAssistantTextMessage(text string) IMessage {
 	return &AssistantMessage{
@@ -129,7 +166,7 @@ func NewToolMessage(id string, content IContent) IMessage {
 // @paramet:api參數
 func NewTool(name, description string, paramet FunctionParameters) Tool {
 	return Tool{
-		Type: "function",
+		Type: ToolType_Function,
 		ToolFunction: ToolFunction{
 			Name:        name,
 			Description: description,
@@ -138,6 +175,23 @@ func NewTool(name, description string, paramet FunctionParameters) Tool {
 	}
 }
 
+// 由 Go struct 生成新 Tool, 參數定義規則同 NewFunctionParametersFor
+//
+// @name:外部呼叫名稱
+// @description:api功能說明
+// @args:參數結構範例 EX: WeatherArgs{}
+// @strict:是否啟用嚴格的架構遵循
+func NewToolFor(name, description string, args interface{}, strict bool) (Tool, error) {
+	params, err := NewFunctionParametersFor(args, strict)
+	if err != nil {
+		return Tool{}, err
+	}
+
+	tool := NewTool(name, description, params)
+	tool.ToolFunction.Strict = strict
+	return tool, nil
+}
+
 // 生成新 FunctionParameters
 //
 // [][3]string 0: key:參數名稱, type:參數資料型態, description:參數說明提供給openAI辨識
@@ -146,6 +200,7 @@ func NewToolFunctionParameters(parameterDatas [][3]string) FunctionParameters {
 	params := FunctionParameters{
 		Type:                 "object",
 		Properties:           make(map[string]Parameter),
+		Required:             []string{},
 		AdditionalProperties: false,
 	}
 	for _, dataRow := range parameterDatas {
@@ -158,3 +213,50 @@ func NewToolFunctionParameters(parameterDatas [][3]string) FunctionParameters {
 
 	return params
 }
+
+// 生成工具選擇, 模型不呼叫任何工具
+func NewToolChoiceNone() IToolChoice {
+	return ToolChoiceString(ToolChoice_None)
+}
+
+// 生成工具選擇, 模型自行決定是否呼叫工具
+func NewToolChoiceAuto() IToolChoice {
+	return ToolChoiceString(ToolChoice_Auto)
+}
+
+// 生成工具選擇, 模型必須呼叫一個或多個工具
+func NewToolChoiceRequired() IToolChoice {
+	return ToolChoiceString(ToolChoice_Required)
+}
+
+// 生成工具選擇, 強制模型呼叫指定函數
+//
+// @name:函數名稱, 需為請求 Tools 中的函數
+func NewToolChoiceFunction(name string) IToolChoice {
+	return &ToolChoiceObject{
+		Type:               ToolType_Function,
+		ToolChoiceFunction: ToolChoiceFunction{Name: name},
+	}
+}
+
+// 解析 tool_choice json, 字串轉為 ToolChoiceString, 物件轉為 *ToolChoiceObject
+func ParseToolChoice(data []byte) (IToolChoice, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) == 0 || bytes.Equal(data, []byte("null")) {
+		return nil, nil
+	}
+
+	if data[0] == '"' {
+		var choice string
+		if err := json.Unmarshal(data, &choice); err != nil {
+			return nil, fmt.Errorf("[ParseToolChoice] Error %v", err)
+		}
+		return ToolChoiceString(choice), nil
+	}
+
+	choice := &ToolChoiceObject{}
+	if err := json.Unmarshal(data, choice); err != nil {
+		return nil, fmt.Errorf("[ParseToolChoice] Error %v", err)
+	}
+	return choice, nil
+}